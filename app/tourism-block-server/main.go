@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -13,6 +14,9 @@ import (
 )
 
 func main() {
+	enforce := flag.Bool("enforce-penalty-rules", false, "enforce the penalty rules triggered by evaluations as the platform admin")
+	flag.Parse()
+
 	log.Println("============ application-golang starts ============")
 
 	err := os.Setenv("DISCOVERY_AS_LOCALHOST", "true")
@@ -25,8 +29,12 @@ func main() {
 		log.Fatalf("Failed to create wallet: %v", err)
 	}
 
-	if !wallet.Exists("appUser") {
-		err = populateWallet(wallet)
+	label, user := "appUser", "User1@org1.example.com"
+	if *enforce {
+		label, user = "platformAdmin", "PlatformAdmin@org1.example.com"
+	}
+	if !wallet.Exists(label) {
+		err = populateWallet(wallet, label, user)
 		if err != nil {
 			log.Fatalf("Failed to populate wallet contents: %v", err)
 		}
@@ -44,7 +52,7 @@ func main() {
 
 	gw, err := gateway.Connect(
 		gateway.WithConfig(config.FromFile(filepath.Clean(ccpPath))),
-		gateway.WithIdentity(wallet, "appUser"),
+		gateway.WithIdentity(wallet, label),
 	)
 	if err != nil {
 		log.Fatalf("Failed to connect to gateway: %v", err)
@@ -58,36 +66,45 @@ func main() {
 
 	contract := network.GetContract("tourism_block")
 
+	if *enforce {
+		log.Println("--> Listen Event: PenaltyRulesTriggered")
+		err = enforcePenaltyRules(contract)
+		if err != nil {
+			log.Fatalf("Failed to listen to penalty rules: %v", err)
+		}
+		return
+	}
+
 	// log.Println("--> Submit Transaction: CreateService")
-	// result, err := contract.SubmitTransaction("CreateService", "5f793bd99b0afd906562d391")
+	// result, err := contract.SubmitTransaction("services:CreateService", "5f793bd99b0afd906562d391")
 	// if err != nil {
 	// 	log.Fatalf("Failed to submit transaction: %v", err)
 	// }
 	// log.Println(string(result))
 
 	// log.Println("--> Submit Transaction: ReadService")
-	// result, err := contract.SubmitTransaction("ReadService", "5f793bd99b0afd906562d390")
+	// result, err := contract.SubmitTransaction("services:ReadService", "5f793bd99b0afd906562d390")
 	// if err != nil {
 	// 	log.Fatalf("Failed to submit transaction: %v", err)
 	// }
 	// log.Println(string(result))
 
 	// log.Println("--> Submit Transaction: AddAgreement")
-	// result, err := contract.SubmitTransaction("AddAgreement", "5f793bd99b0afd906562d390", "5f82dbd1ae82399ce1d95f61", "true")
+	// result, err := contract.SubmitTransaction("agreements:AddAgreement", "5f793bd99b0afd906562d390", "5f82dbd1ae82399ce1d95f61", "true")
 	// if err != nil {
 	// 	log.Fatalf("Failed to submit transaction: %v", err)
 	// }
 	// log.Println(string(result))
 
 	// log.Println("--> Submit Transaction: UpdateAgreement")
-	// result, err := contract.SubmitTransaction("UpdateAgreement", "5f793bd99b0afd906562d390", "5f82dbd1ae82399ce1d95f60", "false")
+	// result, err := contract.SubmitTransaction("agreements:UpdateAgreement", "5f793bd99b0afd906562d390", "5f82dbd1ae82399ce1d95f60", "false")
 	// if err != nil {
 	// 	log.Fatalf("Failed to submit transaction: %v", err)
 	// }
 	// log.Println(string(result))
 
 	// log.Println("--> Submit Transaction: RemoveAgreement")
-	// result, err := contract.SubmitTransaction("RemoveAgreement", "5f793bd99b0afd906562d390", "5f82dbd1ae82399ce1d95f61")
+	// result, err := contract.SubmitTransaction("agreements:RemoveAgreement", "5f793bd99b0afd906562d390", "5f82dbd1ae82399ce1d95f61")
 	// if err != nil {
 	// 	log.Fatalf("Failed to submit transaction: %v", err)
 	// }
@@ -96,30 +113,28 @@ func main() {
 	// now := time.Now()
 	// at := now.Format("2006-01-02T15:04:05.000Z")
 	// log.Println("--> Submit Transaction: HandleSatisfactionEvaluationEvent")
-	// result, err := contract.SubmitTransaction("HandleSatisfactionEvaluationEvent", "5f793bd99b0afd906562d390", "5f82dbd1ae82399ce1d95f60", "5f82e354a2100c7d7dc1a190", "6d976a7b3ef96ed1334de159eeb4aaac", at, "false")
+	// result, err := contract.SubmitTransaction("evaluations:HandleSatisfactionEvaluationEvent", "5f793bd99b0afd906562d390", "5f82dbd1ae82399ce1d95f60", "5f82e354a2100c7d7dc1a190", "6d976a7b3ef96ed1334de159eeb4aaac", at, "false")
 	// if err != nil {
 	// 	log.Fatalf("Failed to submit transaction: %v", err)
 	// }
 	// log.Println(string(result))
 
-	// now := time.Now()
-	// at := now.Format("2006-01-02T15:04:05.000Z")
-	// log.Println("--> Submit Transaction: HandlePenaltyRuleEvaluationEvent")
-	// result, err := contract.SubmitTransaction("HandlePenaltyRuleEvaluationEvent", "5f793bd99b0afd906562d390", "5f82dbd1ae82399ce1d95f60", "5f82e354a2100c7d7dc1a191", "6d976a7b3ef96ed1334de159eeb4aaad", at, "true")
+	// log.Println("--> Submit Transaction: SettleCompensation")
+	// result, err := contract.SubmitTransaction("evaluations:SettleCompensation", "5f82e354a2100c7d7dc1a191", "paid", "6d976a7b3ef96ed1334de159eeb4aaad")
 	// if err != nil {
 	// 	log.Fatalf("Failed to submit transaction: %v", err)
 	// }
 	// log.Println(string(result))
 
 	log.Println("--> Evaluate Transaction: CountAllEvaluations")
-	result, err := contract.EvaluateTransaction("CountAllEvaluations", "1000")
+	result, err := contract.EvaluateTransaction("evaluations:CountAllEvaluations", "1000")
 	if err != nil {
 		log.Fatalf("failed to submit transaction: %v\n", err)
 	}
 	log.Println(string(result))
 }
 
-func populateWallet(wallet *gateway.Wallet) error {
+func populateWallet(wallet *gateway.Wallet, label, user string) error {
 	log.Println("============ Populating wallet ============")
 	credPath := filepath.Join(
 		"/",
@@ -133,7 +148,7 @@ func populateWallet(wallet *gateway.Wallet) error {
 		"peerOrganizations",
 		"org1.example.com",
 		"users",
-		"User1@org1.example.com",
+		user,
 		"msp",
 	)
 
@@ -161,5 +176,5 @@ func populateWallet(wallet *gateway.Wallet) error {
 
 	identity := gateway.NewX509Identity("Org1MSP", string(cert), string(key))
 
-	return wallet.Put("appUser", identity)
+	return wallet.Put(label, identity)
 }
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/gateway"
+)
+
+// penaltyRulesTriggeredEvent is the payload of the PenaltyRulesTriggered chaincode event.
+type penaltyRulesTriggeredEvent struct {
+	TxID     string `json:"txId"`
+	Requests []struct {
+		EvaluationID  string          `json:"evaluationId"`
+		ReservationID string          `json:"reservationId"`
+		AgreementID   string          `json:"agreementId"`
+		PenaltyRules  json.RawMessage `json:"penaltyRules"`
+		Reason        string          `json:"reason"`
+	} `json:"requests"`
+}
+
+// evaluationResult is the evaluation result argument of EnforcePenaltyRuleFromBlockChain.
+type evaluationResult struct {
+	Satisfied     bool            `json:"satisfied"`
+	Score         float32         `json:"score"`
+	PenaltyRules  json.RawMessage `json:"penaltyRules"`
+	FailureReason string          `json:"failureReason,omitempty"`
+}
+
+// enforcePenaltyRules enforces the penalties announced by the PenaltyRulesTriggered events of committed
+// transactions. The chaincode does not call the penalty endpoint while transactions are endorsed, each
+// penalty is enforced by evaluating EnforcePenaltyRuleFromBlockChain once on a peer of the platform org,
+// which keeps the internal service access key. The contract must be used by a platform admin.
+func enforcePenaltyRules(contract *gateway.Contract) error {
+	reg, events, err := contract.RegisterEvent("PenaltyRulesTriggered")
+	if err != nil {
+		return err
+	}
+	defer contract.Unregister(reg)
+
+	for event := range events {
+		var triggered penaltyRulesTriggeredEvent
+		err = json.Unmarshal(event.Payload, &triggered)
+		if err != nil {
+			log.Printf("Failed to unmarshal penalty rules of transaction %s: %v", event.TxID, err)
+			continue
+		}
+
+		for _, req := range triggered.Requests {
+			eResult, err := json.Marshal(&evaluationResult{PenaltyRules: req.PenaltyRules, FailureReason: req.Reason})
+			if err != nil {
+				log.Printf("Failed to marshal penalty rules of evaluation %s: %v", req.EvaluationID, err)
+				continue
+			}
+
+			_, err = contract.EvaluateTransaction("evaluations:EnforcePenaltyRuleFromBlockChain", req.ReservationID, req.AgreementID, string(eResult), "")
+			if err != nil {
+				log.Printf("Failed to enforce penalty rules of evaluation %s: %v", req.EvaluationID, err)
+				continue
+			}
+			log.Printf("Enforced penalty rules of evaluation %s", req.EvaluationID)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build !debug
+// +build !debug
+
+package main
+
+import "github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+// diagnosticsContracts returns no contract, the diagnostics contract is only registered in debug builds.
+func diagnosticsContracts() []contractapi.ContractInterface {
+	return nil
+}
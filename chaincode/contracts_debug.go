@@ -0,0 +1,17 @@
+//go:build debug
+// +build debug
+
+package main
+
+import (
+	"bitbucket.org/quocdaitrn/tourism-block-blockchain/chaincode/smartcontract"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// diagnosticsContracts returns the diagnostics contract registered in debug builds.
+func diagnosticsContracts() []contractapi.ContractInterface {
+	return []contractapi.ContractInterface{
+		&smartcontract.DiagnosticsContract{Contract: contractapi.Contract{Name: "diagnostics"}},
+	}
+}
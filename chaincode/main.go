@@ -9,7 +9,15 @@ import (
 )
 
 func main() {
-	tourismChaincode, err := contractapi.NewChaincode(&smartcontract.SmartContract{})
+	contracts := []contractapi.ContractInterface{
+		&smartcontract.ServicesContract{Contract: contractapi.Contract{Name: "services"}},
+		&smartcontract.AgreementsContract{Contract: contractapi.Contract{Name: "agreements"}},
+		&smartcontract.EvaluationsContract{Contract: contractapi.Contract{Name: "evaluations"}},
+		&smartcontract.AdminContract{Contract: contractapi.Contract{Name: "admin"}},
+	}
+	contracts = append(contracts, diagnosticsContracts()...)
+
+	tourismChaincode, err := contractapi.NewChaincode(contracts...)
 	if err != nil {
 		log.Panicf("Error creating Tourism chaincode: %v", err)
 	}
@@ -0,0 +1,209 @@
+package smartcontract
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// RotateInternalServiceAccessKey creates or replaces the internal service access key with the token
+// passed as transient data, the key expires at the given time.
+func (s *AdminContract) RotateInternalServiceAccessKey(ctx contractapi.TransactionContextInterface, expiresAt string) (*AccessKeyInfo, error) {
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = assertPlatformAdmin(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	transient, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return nil, fmt.Errorf("can not read transient data: %v", err)
+	}
+	token := string(transient[TransientKeyAccessKeyToken])
+	if token == "" {
+		return nil, fmt.Errorf("the access key token must be passed as transient data %s", TransientKeyAccessKeyToken)
+	}
+
+	expiry, err := ParseTime(expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("can not parse expiry time %s: %v", expiresAt, err)
+	}
+	now, err := txTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !expiry.After(now) {
+		return nil, fmt.Errorf("the access key must expire after %s", now.Format(RFC3339))
+	}
+
+	version := 1
+	current, err := getInternalServiceAccessKey(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil {
+		version = current.Version + 1
+	}
+
+	accessKey := &AccessKey{
+		Type:      "Bearer",
+		Token:     token,
+		Version:   version,
+		CreatedAt: now,
+		ExpiresAt: expiry,
+	}
+
+	jAccessKey, err := json.Marshal(accessKey)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ctx.GetStub().PutPrivateData(platformCollection(config), JWTInternalServiceAccessKey, jAccessKey)
+	if err != nil {
+		return nil, fmt.Errorf("can not save the internal service access key: %v", err)
+	}
+
+	// remove the access key stored in plain world state by earlier versions of the chaincode
+	err = ctx.GetStub().DelState(JWTInternalServiceAccessKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return accessKey.info(now), nil
+}
+
+// ReadInternalServiceAccessKey returns the metadata of the internal service access key.
+func (s *AdminContract) ReadInternalServiceAccessKey(ctx contractapi.TransactionContextInterface) (*AccessKeyInfo, error) {
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = assertPlatformAdmin(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	accessKey, err := getInternalServiceAccessKey(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	if accessKey == nil {
+		return nil, fmt.Errorf("the internal service access key does not exist")
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return accessKey.info(now), nil
+}
+
+// readInternalServiceAccessKey returns the internal service access key, it fails when the key
+// does not exist or has expired. The key is only kept on peers of the platform org.
+func readInternalServiceAccessKey(ctx contractapi.TransactionContextInterface, config *ContractConfig) (*AccessKey, error) {
+	accessKey, err := getInternalServiceAccessKey(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	if accessKey == nil {
+		return nil, fmt.Errorf("the internal service access key does not exist")
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !now.Before(accessKey.ExpiresAt) {
+		return nil, fmt.Errorf("the internal service access key has expired at %s", accessKey.ExpiresAt.Format(RFC3339))
+	}
+
+	return accessKey, nil
+}
+
+// getInternalServiceAccessKey returns the internal service access key or nil when it does not exist.
+func getInternalServiceAccessKey(ctx contractapi.TransactionContextInterface, config *ContractConfig) (*AccessKey, error) {
+	jAccessKey, err := ctx.GetStub().GetPrivateData(platformCollection(config), JWTInternalServiceAccessKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the internal service access key: %v", err)
+	}
+	if jAccessKey == nil {
+		return nil, nil
+	}
+
+	var accessKey AccessKey
+	err = json.Unmarshal(jAccessKey, &accessKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &accessKey, nil
+}
+
+// info returns the metadata of the access key at the given time.
+func (k *AccessKey) info(now time.Time) *AccessKeyInfo {
+	return &AccessKeyInfo{
+		Type:      k.Type,
+		Version:   k.Version,
+		CreatedAt: k.CreatedAt,
+		ExpiresAt: k.ExpiresAt,
+		Expired:   !now.Before(k.ExpiresAt),
+	}
+}
+
+// platformCollection returns the implicit private data collection of the platform org.
+func platformCollection(config *ContractConfig) string {
+	return "_implicit_org_" + config.PlatformMSPID
+}
+
+// assertPlatformRole returns an error when the submitting client has none of the given roles or does not
+// belong to the platform org, roles are granted by the platform and are not trusted from other orgs.
+func assertPlatformRole(ctx contractapi.TransactionContextInterface, config *ContractConfig, roles ...string) error {
+	err := assertAnyRole(ctx, roles...)
+	if err != nil {
+		return err
+	}
+
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("can not get the MSP ID of the client: %v", err)
+	}
+	if clientMSPID != config.PlatformMSPID {
+		return fmt.Errorf("the client must belong to the platform org %s", config.PlatformMSPID)
+	}
+	return nil
+}
+
+// assertPlatformAdmin returns an error when the submitting client is not a admin of the platform org
+// or the endorsing peer does not belong to the platform org.
+func assertPlatformAdmin(ctx contractapi.TransactionContextInterface, config *ContractConfig) error {
+	err := assertPlatformRole(ctx, config, RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	peerMSPID, err := shim.GetMSPID()
+	if err != nil {
+		return fmt.Errorf("can not get the MSP ID of the peer: %v", err)
+	}
+	if peerMSPID != config.PlatformMSPID {
+		return fmt.Errorf("the peer must belong to the platform org %s", config.PlatformMSPID)
+	}
+	return nil
+}
+
+// txTime returns the timestamp of the transaction.
+func txTime(ctx contractapi.TransactionContextInterface) (time.Time, error) {
+	ts, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("can not get the transaction timestamp: %v", err)
+	}
+
+	return time.Unix(ts.Seconds, int64(ts.Nanos)).UTC(), nil
+}
@@ -0,0 +1,168 @@
+package smartcontract
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+)
+
+// transientStub is a mock stub with transient data, the mock stub does not implement it.
+type transientStub struct {
+	*shimtest.MockStub
+	transient map[string][]byte
+}
+
+func (s *transientStub) GetTransient() (map[string][]byte, error) {
+	return s.transient, nil
+}
+
+// setPeerMSPID sets the MSP ID of the endorsing peer and returns a function restoring it.
+func setPeerMSPID(mspID string) func() {
+	previous, ok := os.LookupEnv("CORE_PEER_LOCALMSPID")
+	os.Setenv("CORE_PEER_LOCALMSPID", mspID)
+	return func() {
+		if ok {
+			os.Setenv("CORE_PEER_LOCALMSPID", previous)
+			return
+		}
+		os.Unsetenv("CORE_PEER_LOCALMSPID")
+	}
+}
+
+// putTestAccessKey stores the internal service access key in the private data of the platform org.
+func putTestAccessKey(t *testing.T, stub *shimtest.MockStub, accessKey *AccessKey) {
+	t.Helper()
+
+	jAccessKey, err := json.Marshal(accessKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = stub.PutPrivateData(platformCollection(defaultConfig()), JWTInternalServiceAccessKey, jAccessKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRotateInternalServiceAccessKey(t *testing.T) {
+	now := time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		role        string
+		clientMSPID string
+		peerMSPID   string
+		token       string
+		expiresAt   string
+		current     *AccessKey
+		wantErr     bool
+		wantVersion int
+	}{
+		{name: "first key", role: RoleAdmin, peerMSPID: DefaultPlatformMSPID, token: "token1", expiresAt: "2021-07-01T08:00:00.000Z", wantVersion: 1},
+		{name: "rotated key", role: RoleAdmin, peerMSPID: DefaultPlatformMSPID, token: "token2", expiresAt: "2021-07-01T08:00:00.000Z", current: &AccessKey{Type: "Bearer", Token: "token1", Version: 3, CreatedAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)}, wantVersion: 4},
+		{name: "no token", role: RoleAdmin, peerMSPID: DefaultPlatformMSPID, expiresAt: "2021-07-01T08:00:00.000Z", wantErr: true},
+		{name: "expired", role: RoleAdmin, peerMSPID: DefaultPlatformMSPID, token: "token1", expiresAt: "2021-06-01T08:00:00.000Z", wantErr: true},
+		{name: "invalid expiry", role: RoleAdmin, peerMSPID: DefaultPlatformMSPID, token: "token1", expiresAt: "next month", wantErr: true},
+		{name: "provider", role: RoleProvider, peerMSPID: DefaultPlatformMSPID, token: "token1", expiresAt: "2021-07-01T08:00:00.000Z", wantErr: true},
+		{name: "admin of another org", role: RoleAdmin, clientMSPID: "Org2MSP", peerMSPID: DefaultPlatformMSPID, token: "token1", expiresAt: "2021-07-01T08:00:00.000Z", wantErr: true},
+		{name: "peer of another org", role: RoleAdmin, peerMSPID: "Org2MSP", token: "token1", expiresAt: "2021-07-01T08:00:00.000Z", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer setPeerMSPID(tt.peerMSPID)()
+
+			ctx, mockStub := newTestContext(t)
+			setTxTime(mockStub, now)
+			stub := &transientStub{MockStub: mockStub, transient: map[string][]byte{}}
+			if tt.token != "" {
+				stub.transient[TransientKeyAccessKeyToken] = []byte(tt.token)
+			}
+			ctx.SetStub(stub)
+			ctx.SetClientIdentity(&testIdentity{id: tt.role, mspID: tt.clientMSPID, attrs: map[string]string{IdentityAttributeRole: tt.role}})
+			if tt.current != nil {
+				putTestAccessKey(t, mockStub, tt.current)
+			}
+			// a key stored in plain world state by earlier versions of the chaincode
+			err := mockStub.PutState(JWTInternalServiceAccessKey, []byte(`{"key":"legacy"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			info, err := (&AdminContract{}).RotateInternalServiceAccessKey(ctx, tt.expiresAt)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if info.Version != tt.wantVersion {
+				t.Errorf("version = %d, want %d", info.Version, tt.wantVersion)
+			}
+			if info.Expired {
+				t.Errorf("the rotated key is expired")
+			}
+
+			accessKey, err := readInternalServiceAccessKey(ctx, defaultConfig())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if accessKey.Token != tt.token {
+				t.Errorf("token = %s, want %s", accessKey.Token, tt.token)
+			}
+			legacy, err := mockStub.GetState(JWTInternalServiceAccessKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if legacy != nil {
+				t.Errorf("the access key in world state is not removed")
+			}
+		})
+	}
+}
+
+func TestReadInternalServiceAccessKey(t *testing.T) {
+	now := time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		current     *AccessKey
+		wantErr     bool
+		wantExpired bool
+	}{
+		{name: "valid key", current: &AccessKey{Type: "Bearer", Token: "token1", Version: 1, CreatedAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)}},
+		{name: "expired key", current: &AccessKey{Type: "Bearer", Token: "token1", Version: 1, CreatedAt: now.Add(-time.Hour), ExpiresAt: now}, wantExpired: true},
+		{name: "no key", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer setPeerMSPID(DefaultPlatformMSPID)()
+
+			ctx, stub := newTestContext(t)
+			setTxTime(stub, now)
+			setRole(ctx, RoleAdmin, "")
+			if tt.current != nil {
+				putTestAccessKey(t, stub, tt.current)
+			}
+
+			info, err := (&AdminContract{}).ReadInternalServiceAccessKey(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if info.Expired != tt.wantExpired {
+				t.Errorf("expired = %v, want %v", info.Expired, tt.wantExpired)
+			}
+
+			// penalties are only enforced with a key which has not expired
+			_, err = readInternalServiceAccessKey(ctx, defaultConfig())
+			if (err != nil) != tt.wantExpired {
+				t.Errorf("error = %v, want error %v", err, tt.wantExpired)
+			}
+		})
+	}
+}
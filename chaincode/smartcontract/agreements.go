@@ -0,0 +1,347 @@
+package smartcontract
+
+import (
+	b64 "encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// AddAgreement adds a agreement to a service.
+func (s *AgreementsContract) AddAgreement(ctx contractapi.TransactionContextInterface, sid, aid, cat string, hasPenalty bool, items, penaltyRules string) (*Service, error) {
+	exist, err := serviceExists(ctx, sid)
+	if err != nil {
+		return nil, err
+	}
+	if !exist {
+		return nil, fmt.Errorf("the service %s does not exist", sid)
+	}
+
+	service, err := readService(ctx, sid)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range service.Agreements {
+		if a.AgreementID == aid {
+			return nil, fmt.Errorf("the agreement %s already exist", aid)
+		}
+	}
+
+	dItems, err := b64.StdEncoding.DecodeString(items)
+	if err != nil {
+		return nil, fmt.Errorf("can not decode agreement items from base64: %v", err)
+	}
+	var aItems []*AgreementItem
+	err = json.Unmarshal(dItems, &aItems)
+	if err != nil {
+		return nil, fmt.Errorf("can not unmarshal agreement items: %v", err)
+	}
+
+	dPenaltyRules, err := b64.StdEncoding.DecodeString(penaltyRules)
+	if err != nil {
+		return nil, fmt.Errorf("can not decode penalty rules from base64: %v", err)
+	}
+	var aPenaltyRules []*PenaltyRule
+	err = json.Unmarshal(dPenaltyRules, &aPenaltyRules)
+	if err != nil {
+		return nil, fmt.Errorf("can not unmarshal penalty rules: %v", err)
+	}
+
+	c, err := loadCatalogue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateAgreementItems(c, cat, aItems)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validatePenaltyRules(c, aItems, service.ViewRanking, aPenaltyRules)
+	if err != nil {
+		return nil, err
+	}
+
+	agreement := &Agreement{
+		AgreementID:                            aid,
+		Category:                               cat,
+		Items:                                  aItems,
+		TotalFeedbacks:                         0,
+		TotalUnsatisfied:                       0,
+		TotalRuleViolations:                    0,
+		TotalRuleViolationWithoutCompensations: 0,
+		HasPenaltyRule:                         hasPenalty,
+		PenaltyRules:                           aPenaltyRules,
+		RuleAbidingRate:                        1.0,
+		SatisfactionRate:                       1.0,
+		Version:                                1,
+	}
+	service.Agreements = append(service.Agreements, agreement)
+
+	jService, err := json.Marshal(service)
+	err = ctx.GetStub().PutState(sid, jService)
+	if err != nil {
+		return nil, fmt.Errorf("fail to add agreement %s to service %s", aid, sid)
+	}
+
+	return service, nil
+}
+
+// UpdateAgreement updates a agreement in a service.
+func (s *AgreementsContract) UpdateAgreement(ctx contractapi.TransactionContextInterface, sid, aid, cat string, hasPenalty bool, items, penaltyRules string) (*Service, error) {
+	exist, err := serviceExists(ctx, sid)
+	if err != nil {
+		return nil, err
+	}
+	if !exist {
+		return nil, fmt.Errorf("the service %s does not exist", sid)
+	}
+
+	service, err := readService(ctx, sid)
+	if err != nil {
+		return nil, err
+	}
+
+	aIndex := -1
+	for i, a := range service.Agreements {
+		if a.AgreementID == aid {
+			aIndex = i
+			break
+		}
+	}
+	if aIndex == -1 {
+		return nil, fmt.Errorf("the agreement %s does not exist", aid)
+	}
+
+	dItems, err := b64.StdEncoding.DecodeString(items)
+	if err != nil {
+		return nil, fmt.Errorf("can not decode agreement items from base64: %v", err)
+	}
+	var aItems []*AgreementItem
+	err = json.Unmarshal(dItems, &aItems)
+	if err != nil {
+		return nil, fmt.Errorf("can not unmarshal agreement items: %v", err)
+	}
+
+	dPenaltyRules, err := b64.StdEncoding.DecodeString(penaltyRules)
+	if err != nil {
+		return nil, fmt.Errorf("can not decode penalty rules from base64: %v", err)
+	}
+	var aPenaltyRules []*PenaltyRule
+	err = json.Unmarshal(dPenaltyRules, &aPenaltyRules)
+	if err != nil {
+		return nil, fmt.Errorf("can not unmarshal penalty rules: %v", err)
+	}
+
+	c, err := loadCatalogue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateAgreementItems(c, cat, aItems)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validatePenaltyRules(c, aItems, service.ViewRanking, aPenaltyRules)
+	if err != nil {
+		return nil, err
+	}
+
+	service.Agreements[aIndex].Category = cat
+	service.Agreements[aIndex].Items = aItems
+	service.Agreements[aIndex].HasPenaltyRule = hasPenalty
+	service.Agreements[aIndex].PenaltyRules = aPenaltyRules
+	service.Agreements[aIndex].Version++
+
+	jService, err := json.Marshal(service)
+	err = ctx.GetStub().PutState(sid, jService)
+	if err != nil {
+		return nil, fmt.Errorf("fail to update agreement %s in service %s", aid, sid)
+	}
+
+	return service, nil
+}
+
+// SetAgreementPolicy sets how a agreement is verified and rated from a base64 encoded agreement policy.
+// The policy replaces the current one as a whole, the item policy, item pass threshold and satisfaction rate
+// strategy it leaves empty fall back to the contract configuration. The policy caps the penalties of the
+// provider, so it is governed by the platform: only an admin or an arbitrator of the platform org can set it.
+func (s *AgreementsContract) SetAgreementPolicy(ctx contractapi.TransactionContextInterface, sid, aid, policy string) (*Service, error) {
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = assertPlatformRole(ctx, config, RoleAdmin, RoleArbitrator)
+	if err != nil {
+		return nil, err
+	}
+
+	dPolicy, err := b64.StdEncoding.DecodeString(policy)
+	if err != nil {
+		return nil, fmt.Errorf("can not decode agreement policy from base64: %v", err)
+	}
+	var aPolicy AgreementPolicy
+	err = json.Unmarshal(dPolicy, &aPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("can not unmarshal agreement policy: %v", err)
+	}
+
+	err = validateAgreementPolicy(&aPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := readService(ctx, sid)
+	if err != nil {
+		return nil, err
+	}
+
+	agreement, err := findAgreement(service, aid)
+	if err != nil {
+		return nil, err
+	}
+	if aPolicy.BedMatchMode != "" && agreement.Category != AgreementCategoryBed {
+		return nil, fmt.Errorf("agreement %s is not a bed agreement", aid)
+	}
+
+	// the satisfaction rate strategy is not part of the terms of the agreement
+	if agreementPolicyOf(agreement).terms() != aPolicy.terms() {
+		agreement.Version++
+	}
+	agreement.PenaltyCombination = aPolicy.PenaltyCombination
+	agreement.MaxDiscountPercent = aPolicy.MaxDiscountPercent
+	agreement.MaxAmount = aPolicy.MaxAmount
+	agreement.ItemPolicy = aPolicy.ItemPolicy
+	agreement.ItemPassThreshold = aPolicy.ItemPassThreshold
+	agreement.BedMatchMode = aPolicy.BedMatchMode
+	agreement.SatisfactionRateStrategy = aPolicy.SatisfactionRateStrategy
+	refreshSatisfactionRate(config, service, agreement)
+
+	jService, err := json.Marshal(service)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().PutState(sid, jService)
+	if err != nil {
+		return nil, fmt.Errorf("fail to set policy of agreement %s in service %s", aid, sid)
+	}
+
+	return service, nil
+}
+
+// SetViewRanking sets the ranking of views of a service from the lowest to the highest view,
+// an empty ranking restores the default ranking. The ranking decides whether a view is a downgrade,
+// so only an admin of the platform org can set it.
+func (s *AgreementsContract) SetViewRanking(ctx contractapi.TransactionContextInterface, sid string, ranking []string) (*Service, error) {
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = assertPlatformRole(ctx, config, RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := loadCatalogue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, code := range ranking {
+		if !c.hasItem(AgreementCategoryView, code) {
+			return nil, MakeErrorAgreementItemCodeDoesNotSupport(code, AgreementCategoryView)
+		}
+		if StringInSlice(code, ranking[:i]) {
+			return nil, fmt.Errorf("view %s is ranked more than once", code)
+		}
+	}
+
+	service, err := readService(ctx, sid)
+	if err != nil {
+		return nil, err
+	}
+
+	service.ViewRanking = ranking
+	// the ranking is part of the terms of the view agreements of the service, their upgrade
+	// penalties must still have a view to upgrade
+	for _, a := range service.Agreements {
+		if a.Category == AgreementCategoryView {
+			err = validatePenaltyRules(c, a.Items, ranking, a.PenaltyRules)
+			if err != nil {
+				return nil, fmt.Errorf("agreement %s can not be ranked: %v", a.AgreementID, err)
+			}
+			a.Version++
+		}
+	}
+
+	jService, err := json.Marshal(service)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().PutState(sid, jService)
+	if err != nil {
+		return nil, fmt.Errorf("fail to set view ranking of service %s", sid)
+	}
+
+	return service, nil
+}
+
+// RemoveAgreement removes a agreement from a service.
+func (s *AgreementsContract) RemoveAgreement(ctx contractapi.TransactionContextInterface, sid, aid string) (*Service, error) {
+	exist, err := serviceExists(ctx, sid)
+	if err != nil {
+		return nil, err
+	}
+	if !exist {
+		return nil, fmt.Errorf("the service %s does not exist", sid)
+	}
+
+	service, err := readService(ctx, sid)
+	if err != nil {
+		return nil, err
+	}
+
+	aIndex := -1
+	for i, a := range service.Agreements {
+		if a.AgreementID == aid {
+			aIndex = i
+			break
+		}
+	}
+	if aIndex == -1 {
+		return nil, fmt.Errorf("the agreement %s does not exist", aid)
+	}
+
+	agreements := append(service.Agreements[:aIndex], service.Agreements[aIndex+1:]...)
+	service.Agreements = agreements
+
+	minSatisfactionRate := float32(1)
+	minRuleAbidingRate := float32(1)
+	if len(agreements) > 0 {
+		minSatisfactionRate = agreements[0].SatisfactionRate
+		minRuleAbidingRate = agreements[0].RuleAbidingRate
+	}
+
+	for _, a := range agreements {
+		if minSatisfactionRate > a.SatisfactionRate {
+			minSatisfactionRate = a.SatisfactionRate
+		}
+		if minRuleAbidingRate > a.RuleAbidingRate {
+			minRuleAbidingRate = a.RuleAbidingRate
+		}
+	}
+
+	service.SatisfactionRate = minSatisfactionRate
+	service.RuleAbidingRate = minRuleAbidingRate
+
+	jService, err := json.Marshal(service)
+	err = ctx.GetStub().PutState(sid, jService)
+	if err != nil {
+		return nil, fmt.Errorf("fail to remove agreement %s from service %s", aid, sid)
+	}
+
+	return service, nil
+}
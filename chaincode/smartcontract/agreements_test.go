@@ -0,0 +1,179 @@
+package smartcontract
+
+import (
+	b64 "encoding/base64"
+	"encoding/json"
+	"testing"
+)
+
+// encodeAgreementPolicy returns the base64 encoded agreement policy.
+func encodeAgreementPolicy(t *testing.T, p *AgreementPolicy) string {
+	t.Helper()
+
+	jPolicy, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b64.StdEncoding.EncodeToString(jPolicy)
+}
+
+func TestSetAgreementPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		role        string
+		owner       string
+		clientMSPID string
+		aid         string
+		policy      *AgreementPolicy
+		wantErr     bool
+		wantVersion int
+	}{
+		{name: "penalty policy", role: RoleAdmin, aid: "view1", policy: &AgreementPolicy{PenaltyCombination: PenaltyCombinationCumulative, MaxDiscountPercent: 30}, wantVersion: 2},
+		{name: "item policy", role: RoleArbitrator, aid: "view1", policy: &AgreementPolicy{ItemPolicy: ItemPolicyWeighted, ItemPassThreshold: 0.6}, wantVersion: 2},
+		{name: "bed match mode", role: RoleAdmin, aid: "bed1", policy: &AgreementPolicy{BedMatchMode: BedMatchModeUpgradeOnly}, wantVersion: 2},
+		{name: "rate strategy only", role: RoleAdmin, aid: "view1", policy: &AgreementPolicy{SatisfactionRateStrategy: RateStrategyScore}, wantVersion: 1},
+		{name: "unchanged policy", role: RoleAdmin, aid: "view1", policy: &AgreementPolicy{}, wantVersion: 1},
+		{name: "provider of the service", role: RoleProvider, owner: "service1", aid: "view1", policy: &AgreementPolicy{MaxDiscountPercent: 100}, wantErr: true},
+		{name: "admin of another org", role: RoleAdmin, clientMSPID: "Org2MSP", aid: "view1", policy: &AgreementPolicy{}, wantErr: true},
+		{name: "unknown agreement", role: RoleAdmin, aid: "view2", policy: &AgreementPolicy{}, wantErr: true},
+		{name: "unsupported combination", role: RoleAdmin, aid: "view1", policy: &AgreementPolicy{PenaltyCombination: "max"}, wantErr: true},
+		{name: "discount over 100 percent", role: RoleAdmin, aid: "view1", policy: &AgreementPolicy{MaxDiscountPercent: 120}, wantErr: true},
+		{name: "unsupported item policy", role: RoleAdmin, aid: "view1", policy: &AgreementPolicy{ItemPolicy: "any"}, wantErr: true},
+		{name: "pass threshold over 1", role: RoleAdmin, aid: "view1", policy: &AgreementPolicy{ItemPassThreshold: 1.5}, wantErr: true},
+		{name: "unsupported rate strategy", role: RoleAdmin, aid: "view1", policy: &AgreementPolicy{SatisfactionRateStrategy: "median"}, wantErr: true},
+		{name: "bed match mode of view agreement", role: RoleAdmin, aid: "view1", policy: &AgreementPolicy{BedMatchMode: BedMatchModeExact}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, stub := newTestContext(t)
+			putTestService(t, stub, &Service{
+				ServiceID: "service1",
+				Agreements: []*Agreement{
+					{AgreementID: "view1", Category: AgreementCategoryView, Version: 1},
+					{AgreementID: "bed1", Category: AgreementCategoryBed, Version: 1},
+				},
+			})
+			attrs := map[string]string{IdentityAttributeRole: tt.role}
+			if tt.owner != "" {
+				attrs[IdentityAttributeServiceID] = tt.owner
+			}
+			ctx.SetClientIdentity(&testIdentity{id: tt.role, mspID: tt.clientMSPID, attrs: attrs})
+
+			service, err := (&AgreementsContract{}).SetAgreementPolicy(ctx, "service1", tt.aid, encodeAgreementPolicy(t, tt.policy))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			agreement, err := findAgreement(service, tt.aid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := agreementPolicyOf(agreement); got != *tt.policy {
+				t.Errorf("policy = %+v, want %+v", got, *tt.policy)
+			}
+			if agreement.Version != tt.wantVersion {
+				t.Errorf("version = %d, want %d", agreement.Version, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestSetAgreementPolicyRateStrategyPrecedence(t *testing.T) {
+	tests := []struct {
+		name           string
+		configStrategy string
+		strategy       string
+		wantRate       float32
+	}{
+		{name: "agreement strategy wins", configStrategy: RateStrategyCount, strategy: RateStrategyScore, wantRate: 0.9},
+		{name: "agreement count wins over config score", configStrategy: RateStrategyScore, strategy: RateStrategyCount, wantRate: 0.5},
+		{name: "config strategy is the fallback", configStrategy: RateStrategyScore, wantRate: 0.9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, stub := newTestContext(t)
+			config := defaultConfig()
+			config.RateStrategy = tt.configStrategy
+			jConfig, err := json.Marshal(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = stub.PutState(contractConfigKey, jConfig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			putTestService(t, stub, &Service{
+				ServiceID: "service1",
+				Agreements: []*Agreement{{
+					AgreementID:      "view1",
+					Category:         AgreementCategoryView,
+					TotalFeedbacks:   2,
+					TotalUnsatisfied: 1,
+					ScoredFeedbacks:  2,
+					AverageScore:     0.9,
+				}},
+			})
+			setRole(ctx, RoleAdmin, "")
+
+			policy := &AgreementPolicy{SatisfactionRateStrategy: tt.strategy}
+			service, err := (&AgreementsContract{}).SetAgreementPolicy(ctx, "service1", "view1", encodeAgreementPolicy(t, policy))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !floatEqual(service.Agreements[0].SatisfactionRate, tt.wantRate) {
+				t.Errorf("satisfaction rate = %v, want %v", service.Agreements[0].SatisfactionRate, tt.wantRate)
+			}
+		})
+	}
+}
+
+func TestSetViewRanking(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    string
+		owner   string
+		ranking []string
+		wantErr bool
+	}{
+		{name: "admin", role: RoleAdmin, ranking: []string{AgreementItemCodeViewCity, AgreementItemCodeViewGarden, AgreementItemCodeViewSea}},
+		{name: "view of a upgrade penalty not ranked", role: RoleAdmin, ranking: []string{AgreementItemCodeViewCity, AgreementItemCodeViewSea}, wantErr: true},
+		{name: "default ranking", role: RoleAdmin},
+		{name: "provider of the service", role: RoleProvider, owner: "service1", ranking: []string{AgreementItemCodeViewSea}, wantErr: true},
+		{name: "arbitrator", role: RoleArbitrator, ranking: []string{AgreementItemCodeViewSea}, wantErr: true},
+		{name: "view ranked twice", role: RoleAdmin, ranking: []string{AgreementItemCodeViewSea, AgreementItemCodeViewSea}, wantErr: true},
+		{name: "not a view", role: RoleAdmin, ranking: []string{AgreementItemCodeBedKing}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, stub := newTestContext(t)
+			putTestService(t, stub, &Service{
+				ServiceID: "service1",
+				Agreements: []*Agreement{{
+					AgreementID:  "view1",
+					Category:     AgreementCategoryView,
+					Version:      1,
+					Items:        []*AgreementItem{{Code: AgreementItemCodeViewGarden}},
+					PenaltyRules: []*PenaltyRule{{Type: PenaltyRuleTypeUpgradeLevel, UpgradeDimension: UpgradeDimensionView, UpgradeLevels: 1}},
+				}},
+			})
+			setRole(ctx, tt.role, tt.owner)
+
+			service, err := (&AgreementsContract{}).SetViewRanking(ctx, "service1", tt.ranking)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if service.Agreements[0].Version != 2 {
+				t.Errorf("version = %d, want 2", service.Agreements[0].Version)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package smartcontract
+
+import (
+	b64 "encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// SubmitEvaluationBatch records a batch of satisfaction evaluations of any services and agreements
+// in one transaction. In all_or_nothing mode the transaction fails when any submission is rejected,
+// in best_effort mode rejected submissions are reported in their results and the others are recorded.
+// Evaluation ids must be new, the penalties of the batch are announced by one PenaltyRulesTriggered event.
+func (s *EvaluationsContract) SubmitEvaluationBatch(ctx contractapi.TransactionContextInterface, submissions, mode string) ([]*EvaluationSubmissionResult, error) {
+	if mode == "" {
+		mode = BatchModeAllOrNothing
+	}
+	if mode != BatchModeAllOrNothing && mode != BatchModeBestEffort {
+		return nil, fmt.Errorf("batch mode %s has not supported yet", mode)
+	}
+
+	dSubmissions, err := b64.StdEncoding.DecodeString(submissions)
+	if err != nil {
+		return nil, fmt.Errorf("can not decode submissions from base64: %v", err)
+	}
+	var eSubmissions []*EvaluationSubmission
+	err = json.Unmarshal(dSubmissions, &eSubmissions)
+	if err != nil {
+		return nil, fmt.Errorf("can not unmarshal submissions: %v", err)
+	}
+	if len(eSubmissions) == 0 {
+		return nil, fmt.Errorf("the batch has no submission")
+	}
+
+	recorder, err := newEvaluationRecorder(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*EvaluationSubmissionResult, 0, len(eSubmissions))
+	for i, submission := range eSubmissions {
+		result := &EvaluationSubmissionResult{
+			EvaluationID: submission.EvaluationID,
+			ServiceID:    submission.ServiceID,
+			AgreementID:  submission.AgreementID,
+		}
+		results = append(results, result)
+
+		service, agreement, eResult, err := s.checkSubmission(ctx, recorder, submission)
+		if err != nil {
+			if mode == BatchModeAllOrNothing {
+				return nil, fmt.Errorf("submission %d is rejected: %v", i, err)
+			}
+			result.Error = err.Error()
+			continue
+		}
+
+		// the checks cover every application error of recording, a checked submission can only fail
+		// to be recorded on the world state, which fails the batch
+		err = s.recordSatisfactionEvaluation(ctx, recorder, service, agreement, submission, eResult)
+		if err != nil {
+			return nil, fmt.Errorf("submission %d can not be recorded: %v", i, err)
+		}
+		result.Recorded = true
+	}
+
+	err = recorder.save(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,200 @@
+package smartcontract
+
+import (
+	b64 "encoding/base64"
+	"encoding/json"
+	"testing"
+)
+
+func TestSubmitEvaluationBatch(t *testing.T) {
+	const brokenServiceID = "broken"
+	submission := func(sid, eid, rid, at string, satisfied bool) *EvaluationSubmission {
+		return &EvaluationSubmission{ServiceID: sid, AgreementID: recorderAgreementID, EvaluationID: eid, ReservationID: rid,
+			At: at, Satisfied: satisfied, EnforcePenaltyRule: true}
+	}
+
+	tests := []struct {
+		name        string
+		mode        string
+		submissions []*EvaluationSubmission
+
+		wantErr  bool
+		recorded []bool
+	}{
+		{
+			name: "all or nothing",
+			submissions: []*EvaluationSubmission{
+				submission(recorderServiceID, "e1", "r1", recorderAt, true),
+				submission(recorderServiceID, "e2", "r2", recorderAt, false),
+			},
+			recorded: []bool{true, true},
+		},
+		{
+			name: "all or nothing with invalid time",
+			mode: BatchModeAllOrNothing,
+			submissions: []*EvaluationSubmission{
+				submission(recorderServiceID, "e1", "r1", recorderAt, true),
+				submission(recorderServiceID, "e2", "r2", "yesterday", false),
+			},
+			wantErr: true,
+		},
+		{
+			name: "best effort with invalid time",
+			mode: BatchModeBestEffort,
+			submissions: []*EvaluationSubmission{
+				submission(recorderServiceID, "e1", "r1", recorderAt, false),
+				submission(recorderServiceID, "e2", "r2", "yesterday", false),
+				submission(recorderServiceID, "e3", "r3", recorderAt, false),
+			},
+			recorded: []bool{true, false, true},
+		},
+		{
+			name: "best effort with existing compensation",
+			mode: BatchModeBestEffort,
+			submissions: []*EvaluationSubmission{
+				submission(recorderServiceID, "compensated", "r1", recorderAt, false),
+				submission(recorderServiceID, "e2", "r2", recorderAt, false),
+			},
+			recorded: []bool{false, true},
+		},
+		{
+			name: "best effort with penalty which can not be selected",
+			mode: BatchModeBestEffort,
+			submissions: []*EvaluationSubmission{
+				submission(brokenServiceID, "e1", "r1", recorderAt, false),
+				submission(brokenServiceID, "e2", "r2", recorderAt, true),
+				submission(recorderServiceID, "e3", "r3", recorderAt, false),
+			},
+			recorded: []bool{false, true, true},
+		},
+		{
+			name: "best effort with duplicated evaluation and feedback",
+			mode: BatchModeBestEffort,
+			submissions: []*EvaluationSubmission{
+				submission(recorderServiceID, "e1", "r1", recorderAt, true),
+				submission(recorderServiceID, "e1", "r2", recorderAt, true),
+				submission(recorderServiceID, "e3", "r1", recorderAt, true),
+			},
+			recorded: []bool{true, false, false},
+		},
+		{
+			name:        "unknown mode",
+			mode:        "partial",
+			submissions: []*EvaluationSubmission{submission(recorderServiceID, "e1", "r1", recorderAt, true)},
+			wantErr:     true,
+		},
+		{
+			name:    "empty batch",
+			wantErr: true,
+		},
+	}
+
+	s := &EvaluationsContract{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, stub := newTestContext(t)
+			putTestService(t, stub, shuttleService(recorderServiceID))
+
+			// the upgrade_level rule of the broken service has nothing to upgrade in it's agreement
+			putTestService(t, stub, shuttleService(brokenServiceID))
+			broken, err := readService(ctx, brokenServiceID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			broken.Agreements[0].PenaltyRules = []*PenaltyRule{{Type: PenaltyRuleTypeUpgradeLevel, UpgradeDimension: UpgradeDimensionView, UpgradeLevels: 1}}
+			putTestService(t, stub, broken)
+
+			compensationKey, err := stub.CreateCompositeKey(compensationObjectType, []string{"compensated"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = stub.PutState(compensationKey, []byte(`{"compensationId":"compensated"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = stub.PutState("evaluated", []byte(`{"evaluationId":"evaluated"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			jSubmissions, err := json.Marshal(tt.submissions)
+			if err != nil {
+				t.Fatal(err)
+			}
+			results, err := s.SubmitEvaluationBatch(ctx, b64.StdEncoding.EncodeToString(jSubmissions), tt.mode)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			numRecorded := 0
+			for i, result := range results {
+				if result.Recorded != tt.recorded[i] {
+					t.Errorf("submission %d recorded = %v (%s), want %v", i, result.Recorded, result.Error, tt.recorded[i])
+				}
+				if result.Recorded == (result.Error != "") {
+					t.Errorf("submission %d recorded = %v with error %q", i, result.Recorded, result.Error)
+				}
+				if result.Recorded {
+					numRecorded++
+				}
+			}
+
+			numEvaluations := 0
+			for _, sid := range []string{recorderServiceID, brokenServiceID} {
+				service, err := readService(ctx, sid)
+				if err != nil {
+					t.Fatal(err)
+				}
+				numEvaluations += int(service.NumberOfEvaluations)
+			}
+			if numEvaluations != numRecorded {
+				t.Errorf("evaluations = %d, want %d", numEvaluations, numRecorded)
+			}
+		})
+	}
+}
+
+func TestSubmitEvaluationBatchEvent(t *testing.T) {
+	ctx, stub := newTestContext(t)
+	putTestService(t, stub, shuttleService(recorderServiceID))
+	config := defaultConfig()
+	jConfig, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = stub.PutState(contractConfigKey, jConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	submissions := []*EvaluationSubmission{
+		{ServiceID: recorderServiceID, AgreementID: recorderAgreementID, EvaluationID: "e1", ReservationID: "r1", At: recorderAt, EnforcePenaltyRule: true},
+		{ServiceID: recorderServiceID, AgreementID: recorderAgreementID, EvaluationID: "e2", ReservationID: "r2", At: recorderAt, Satisfied: true, EnforcePenaltyRule: true},
+		{ServiceID: recorderServiceID, AgreementID: recorderAgreementID, EvaluationID: "e3", ReservationID: "r3", At: recorderAt, EnforcePenaltyRule: true},
+	}
+	jSubmissions, err := json.Marshal(submissions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = (&EvaluationsContract{}).SubmitEvaluationBatch(ctx, b64.StdEncoding.EncodeToString(jSubmissions), BatchModeAllOrNothing)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the penalties of the batch are announced by one event and enforced after the batch is committed
+	payloads := chaincodeEvents(stub)[EventPenaltyRulesTriggered]
+	if len(payloads) != 1 {
+		t.Fatalf("%d %s events, want 1", len(payloads), EventPenaltyRulesTriggered)
+	}
+	var event PenaltyRulesTriggeredEvent
+	err = json.Unmarshal(payloads[0], &event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(event.Requests) != 2 || event.Requests[0].EvaluationID != "e1" || event.Requests[1].EvaluationID != "e3" {
+		t.Errorf("requests = %+v, want the penalties of e1 and e3", event.Requests)
+	}
+}
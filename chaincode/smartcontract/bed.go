@@ -0,0 +1,151 @@
+package smartcontract
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// VerifyBedAgreement verify bed agreement. Beds of the agreement are matched against
+// the beds of the room by the bed match mode of the agreement:
+//   - exact: the room has exactly the agreed beds.
+//   - at_least_equivalent: the room has at least as many beds and as many bed points.
+//   - upgrade_only: every agreed bed is replaced by a bed of the same or a higher kind.
+func (s *EvaluationsContract) VerifyBedAgreement(ctx contractapi.TransactionContextInterface, a *Agreement, data []*EvaluationData) (*EvaluationResult, error) {
+	return verifyBedAgreement(ctx, a, facilityPayloads(payloadsOf(AgreementCategoryBed, data)))
+}
+
+// verifyBedAgreement verifies bed agreement against the bed payloads of the room.
+func verifyBedAgreement(ctx contractapi.TransactionContextInterface, a *Agreement, data []*facilityPayload) (*EvaluationResult, error) {
+	c, err := loadCatalogue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	points := c.ranks(AgreementCategoryBed)
+
+	agreed := make(map[string]int)
+	for _, agreementItem := range a.Items {
+		agreed[agreementItem.Code] += bedQuantity(agreementItem.Quantity)
+	}
+	actual := make(map[string]int)
+	for _, item := range data {
+		if _, ok := points[item.Code]; !ok {
+			return nil, MakeErrorAgreementItemCodeDoesNotSupport(item.Code, AgreementCategoryBed)
+		}
+		actual[item.Code] += bedQuantity(item.Quantity)
+	}
+
+	var satisfied bool
+	var score float32
+	switch a.BedMatchMode {
+	case BedMatchModeExact:
+		satisfied, score = matchExactBeds(agreed, actual)
+	case "", BedMatchModeAtLeastEquivalent:
+		satisfied, score = matchEquivalentBeds(points, agreed, actual)
+	case BedMatchModeUpgradeOnly:
+		satisfied, score = matchUpgradedBeds(points, agreed, actual)
+	default:
+		return nil, fmt.Errorf("bed match mode %s has not supported yet", a.BedMatchMode)
+	}
+
+	if satisfied {
+		return &EvaluationResult{
+			Satisfied: true,
+			Score:     score,
+		}, nil
+	}
+
+	return &EvaluationResult{
+		Satisfied:     false,
+		PenaltyRules:  defaultPenaltyRules(a, 0),
+		FailureReason: "beds of the room do not match the agreed beds",
+		Score:         score,
+	}, nil
+}
+
+// bedQuantity returns the number of beds of an item, an item without quantity is a single bed.
+func bedQuantity(quantity int) int {
+	if quantity <= 0 {
+		return 1
+	}
+
+	return quantity
+}
+
+// matchExactBeds requires the same kinds and numbers of beds. The score is the ratio of
+// matched beds over the larger number of beds.
+func matchExactBeds(agreed, actual map[string]int) (bool, float32) {
+	satisfied := len(agreed) == len(actual)
+	var matched, numAgreed, numActual int
+	for code, quantity := range agreed {
+		numAgreed += quantity
+		if actual[code] != quantity {
+			satisfied = false
+		}
+		if actual[code] < quantity {
+			matched += actual[code]
+		} else {
+			matched += quantity
+		}
+	}
+	for _, quantity := range actual {
+		numActual += quantity
+	}
+
+	if numActual > numAgreed {
+		numAgreed = numActual
+	}
+
+	return satisfied, ratioScore(float64(matched), float64(numAgreed))
+}
+
+// matchEquivalentBeds requires at least as many beds and bed points as agreed, quantities included.
+func matchEquivalentBeds(points, agreed, actual map[string]int) (bool, float32) {
+	var aNumOfBeds, rNumOfBeds, aTotalPoints, rTotalPoints int
+	for code, quantity := range agreed {
+		aNumOfBeds += quantity
+		aTotalPoints += points[code] * quantity
+	}
+	for code, quantity := range actual {
+		rNumOfBeds += quantity
+		rTotalPoints += points[code] * quantity
+	}
+
+	score := ratioScore(float64(rNumOfBeds), float64(aNumOfBeds))
+	if pointScore := ratioScore(float64(rTotalPoints), float64(aTotalPoints)); pointScore < score {
+		score = pointScore
+	}
+
+	return rNumOfBeds >= aNumOfBeds && rTotalPoints >= aTotalPoints, score
+}
+
+// matchUpgradedBeds matches every agreed bed with a distinct bed of the room having the same
+// or more points. The score is the ratio of matched agreed beds.
+func matchUpgradedBeds(points, agreed, actual map[string]int) (bool, float32) {
+	aPoints := expandBedPoints(points, agreed)
+	rPoints := expandBedPoints(points, actual)
+
+	// the smallest fitting bed of the room is matched with the smallest unmatched agreed bed
+	matched := 0
+	for _, rPoint := range rPoints {
+		if matched < len(aPoints) && rPoint >= aPoints[matched] {
+			matched++
+		}
+	}
+
+	return matched == len(aPoints), ratioScore(float64(matched), float64(len(aPoints)))
+}
+
+// expandBedPoints returns points of every single bed in ascending order.
+func expandBedPoints(points, beds map[string]int) []int {
+	var bedPoints []int
+	for code, quantity := range beds {
+		for i := 0; i < quantity; i++ {
+			bedPoints = append(bedPoints, points[code])
+		}
+	}
+	sort.Ints(bedPoints)
+
+	return bedPoints
+}
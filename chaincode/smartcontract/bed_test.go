@@ -0,0 +1,172 @@
+package smartcontract
+
+import "testing"
+
+func TestVerifyBedAgreement(t *testing.T) {
+	ctx, _ := newTestContext(t)
+	s := &EvaluationsContract{}
+
+	beds := func(codes ...string) []*AgreementItem {
+		var items []*AgreementItem
+		for _, code := range codes {
+			items = append(items, &AgreementItem{Code: code, Quantity: 1})
+		}
+		return items
+	}
+	room := func(codes ...string) []*EvaluationData {
+		var data []*EvaluationData
+		for _, code := range codes {
+			data = append(data, &EvaluationData{Code: code, Quantity: 1})
+		}
+		return data
+	}
+
+	tests := []struct {
+		name      string
+		mode      string
+		agreed    []*AgreementItem
+		data      []*EvaluationData
+		wantErr   bool
+		satisfied bool
+		score     float32
+	}{
+		{
+			name:      "equivalent same beds",
+			agreed:    beds(AgreementItemCodeBedKing),
+			data:      room(AgreementItemCodeBedKing),
+			satisfied: true,
+			score:     1,
+		},
+		{
+			name:   "equivalent two queens are not a king",
+			agreed: beds(AgreementItemCodeBedKing),
+			data:   room(AgreementItemCodeBedQueen, AgreementItemCodeBedQueen),
+			score:  0.2,
+		},
+		{
+			name:   "equivalent king for two twins has too few beds",
+			agreed: beds(AgreementItemCodeBedTwin, AgreementItemCodeBedTwin),
+			data:   room(AgreementItemCodeBedKing),
+			score:  0.5,
+		},
+		{
+			name:      "equivalent queen for double",
+			mode:      BedMatchModeAtLeastEquivalent,
+			agreed:    beds(AgreementItemCodeBedDouble),
+			data:      room(AgreementItemCodeBedQueen),
+			satisfied: true,
+			score:     1,
+		},
+		{
+			name:      "equivalent double and sofa bed for two twins",
+			agreed:    beds(AgreementItemCodeBedTwin, AgreementItemCodeBedTwin),
+			data:      room(AgreementItemCodeBedDouble, AgreementItemCodeBedSofaBed),
+			satisfied: true,
+			score:     1,
+		},
+		{
+			name:   "equivalent crib for twin",
+			agreed: beds(AgreementItemCodeBedTwin),
+			data:   room(AgreementItemCodeBedCrib),
+			score:  0.2,
+		},
+		{
+			name:      "equivalent bed without quantity",
+			agreed:    []*AgreementItem{{Code: AgreementItemCodeBedBunk}},
+			data:      []*EvaluationData{{Code: AgreementItemCodeBedBunk}},
+			satisfied: true,
+			score:     1,
+		},
+		{
+			name:      "exact same beds",
+			mode:      BedMatchModeExact,
+			agreed:    []*AgreementItem{{Code: AgreementItemCodeBedTwin, Quantity: 2}},
+			data:      room(AgreementItemCodeBedTwin, AgreementItemCodeBedTwin),
+			satisfied: true,
+			score:     1,
+		},
+		{
+			name:   "exact bunk for twin",
+			mode:   BedMatchModeExact,
+			agreed: beds(AgreementItemCodeBedTwin),
+			data:   room(AgreementItemCodeBedBunk),
+			score:  0,
+		},
+		{
+			name:   "exact extra crib",
+			mode:   BedMatchModeExact,
+			agreed: beds(AgreementItemCodeBedTwin, AgreementItemCodeBedTwin),
+			data:   room(AgreementItemCodeBedTwin, AgreementItemCodeBedTwin, AgreementItemCodeBedCrib),
+			score:  float32(2) / 3,
+		},
+		{
+			name:      "upgrade king for queen",
+			mode:      BedMatchModeUpgradeOnly,
+			agreed:    beds(AgreementItemCodeBedQueen),
+			data:      room(AgreementItemCodeBedKing),
+			satisfied: true,
+			score:     1,
+		},
+		{
+			name:   "upgrade two twins for double",
+			mode:   BedMatchModeUpgradeOnly,
+			agreed: beds(AgreementItemCodeBedDouble),
+			data:   room(AgreementItemCodeBedTwin, AgreementItemCodeBedTwin),
+			score:  0,
+		},
+		{
+			name:      "upgrade double and crib for twin and crib",
+			mode:      BedMatchModeUpgradeOnly,
+			agreed:    beds(AgreementItemCodeBedTwin, AgreementItemCodeBedCrib),
+			data:      room(AgreementItemCodeBedCrib, AgreementItemCodeBedDouble),
+			satisfied: true,
+			score:     1,
+		},
+		{
+			name:   "upgrade sofa bed for bunk",
+			mode:   BedMatchModeUpgradeOnly,
+			agreed: beds(AgreementItemCodeBedBunk, AgreementItemCodeBedTwin),
+			data:   room(AgreementItemCodeBedSofaBed, AgreementItemCodeBedTwin),
+			score:  0.5,
+		},
+		{
+			name:    "unknown bed",
+			agreed:  beds(AgreementItemCodeBedTwin),
+			data:    room(AgreementItemCodeViewSea),
+			wantErr: true,
+		},
+		{
+			name:    "unknown mode",
+			mode:    "similar",
+			agreed:  beds(AgreementItemCodeBedTwin),
+			data:    room(AgreementItemCodeBedTwin),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agreement := &Agreement{
+				Category:       AgreementCategoryBed,
+				Items:          tt.agreed,
+				BedMatchMode:   tt.mode,
+				HasPenaltyRule: true,
+				PenaltyRules:   []*PenaltyRule{{Type: PenaltyRuleTypeDiscount, DiscountPercent: 10}},
+			}
+			eResult, err := s.VerifyBedAgreement(ctx, agreement, tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if eResult.Satisfied != tt.satisfied {
+				t.Errorf("satisfied = %v, want %v", eResult.Satisfied, tt.satisfied)
+			}
+			if !floatEqual(eResult.Score, tt.score) {
+				t.Errorf("score = %v, want %v", eResult.Score, tt.score)
+			}
+		})
+	}
+}
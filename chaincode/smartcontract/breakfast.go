@@ -0,0 +1,92 @@
+package smartcontract
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// validateBreakfastItem validates the breakfast window of a breakfast item.
+func validateBreakfastItem(item *AgreementItem) error {
+	openAt, err := parseClock(item.BreakfastOpenTime)
+	if err != nil {
+		return fmt.Errorf("invalid breakfast open time of agreement item %s: %v", item.Code, err)
+	}
+	closeAt, err := parseClock(item.BreakfastCloseTime)
+	if err != nil {
+		return fmt.Errorf("invalid breakfast close time of agreement item %s: %v", item.Code, err)
+	}
+	if closeAt <= openAt {
+		return fmt.Errorf("breakfast of agreement item %s must close after it opens", item.Code)
+	}
+	if item.BreakfastGraceMinutes < 0 || item.MaxMissedDays < 0 {
+		return fmt.Errorf("grace minutes and max missed days of agreement item %s must not be negative", item.Code)
+	}
+
+	return nil
+}
+
+// VerifyBreakfastAgreement verify breakfast agreement. Breakfast is missed on a day of the stay when
+// the day is not reported, breakfast was not served, opened later or closed earlier than the agreed
+// window by more than the grace minutes. Reported days outside the stay are ignored.
+// The agreement is unsatisfied when more days than MaxMissedDays are missed.
+func (s *EvaluationsContract) VerifyBreakfastAgreement(_ contractapi.TransactionContextInterface, a *Agreement, data *EvaluationData) (*EvaluationResult, error) {
+	p, ok := payloadOf(AgreementCategoryService, data).(*breakfastPayload)
+	if !ok {
+		return nil, fmt.Errorf("evaluation data code %s is not breakfast service item code", data.Code)
+	}
+
+	return verifyBreakfastAgreement(a, p)
+}
+
+// verifyBreakfastAgreement verifies breakfast agreement against the breakfast payload.
+func verifyBreakfastAgreement(a *Agreement, data *breakfastPayload) (*EvaluationResult, error) {
+	st, err := newStay(data.StayStartDate, data.StayDays)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stay of breakfast: %v", err)
+	}
+
+	agreementItem := a.Items[0]
+	openAt, err := parseClock(agreementItem.BreakfastOpenTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid breakfast open time of agreement item %s: %v", agreementItem.Code, err)
+	}
+	closeAt, err := parseClock(agreementItem.BreakfastCloseTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid breakfast close time of agreement item %s: %v", agreementItem.Code, err)
+	}
+
+	// breakfast is counted once a day
+	servedDays := make(map[string]bool)
+	for _, day := range data.BreakfastDays {
+		if !st.has(day.Date) {
+			continue
+		}
+
+		switch {
+		case day.OpenAt.IsZero() || day.CloseAt.IsZero():
+		case clockOf(day.OpenAt) > openAt+agreementItem.BreakfastGraceMinutes:
+		case clockOf(day.CloseAt) < closeAt-agreementItem.BreakfastGraceMinutes:
+		default:
+			servedDays[day.Date] = true
+		}
+	}
+
+	missedDays := data.StayDays - len(servedDays)
+	score := ratioScore(float64(len(servedDays)), float64(data.StayDays))
+	if missedDays <= agreementItem.MaxMissedDays {
+		return &EvaluationResult{
+			Satisfied: true,
+			Failures:  missedDays,
+			Score:     score,
+		}, nil
+	}
+
+	return &EvaluationResult{
+		Satisfied:     false,
+		PenaltyRules:  defaultPenaltyRules(a, 0),
+		FailureReason: fmt.Sprintf("breakfast was not served as agreed on %d of %d days", missedDays, data.StayDays),
+		Failures:      missedDays,
+		Score:         score,
+	}, nil
+}
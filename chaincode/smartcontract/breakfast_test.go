@@ -0,0 +1,145 @@
+package smartcontract
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerifyBreakfastAgreement(t *testing.T) {
+	agreement := &Agreement{
+		Category: AgreementCategoryService,
+		Items: []*AgreementItem{{
+			Code:                  AgreementItemCodeServiceBreakfast,
+			BreakfastOpenTime:     "06:30",
+			BreakfastCloseTime:    "10:00",
+			BreakfastGraceMinutes: 10,
+			MaxMissedDays:         1,
+		}},
+		HasPenaltyRule: true,
+		PenaltyRules:   []*PenaltyRule{{Type: PenaltyRuleTypeDiscount, DiscountPercent: 10}},
+	}
+
+	day := func(date, openAt, closeAt string) *BreakfastDay {
+		clock := func(s string) time.Time {
+			if s == "" {
+				return time.Time{}
+			}
+			t, err := time.Parse(DateLayout+" 15:04", date+" "+s)
+			if err != nil {
+				panic(err)
+			}
+			return t
+		}
+		return &BreakfastDay{Date: date, OpenAt: clock(openAt), CloseAt: clock(closeAt)}
+	}
+
+	tests := []struct {
+		name      string
+		stayStart string
+		stayDays  int
+		days      []*BreakfastDay
+		wantErr   bool
+		satisfied bool
+		failures  int
+		score     float32
+	}{
+		{
+			name:      "served every day",
+			stayStart: "2021-05-01",
+			stayDays:  2,
+			days:      []*BreakfastDay{day("2021-05-01", "06:30", "10:00"), day("2021-05-02", "06:40", "09:50")},
+			satisfied: true,
+			score:     1,
+		},
+		{
+			name:      "opened late on one day",
+			stayStart: "2021-05-01",
+			stayDays:  2,
+			days:      []*BreakfastDay{day("2021-05-01", "06:41", "10:00"), day("2021-05-02", "06:30", "10:00")},
+			satisfied: true,
+			failures:  1,
+			score:     0.5,
+		},
+		{
+			name:      "closed early and not served",
+			stayStart: "2021-05-01",
+			stayDays:  3,
+			days:      []*BreakfastDay{day("2021-05-01", "06:30", "09:49"), day("2021-05-02", "", ""), day("2021-05-03", "06:30", "10:00")},
+			failures:  2,
+			score:     float32(1) / 3,
+		},
+		{
+			name:      "unreported days are missed",
+			stayStart: "2021-05-01",
+			stayDays:  3,
+			days:      []*BreakfastDay{day("2021-05-02", "06:30", "10:00")},
+			failures:  2,
+			score:     float32(1) / 3,
+		},
+		{
+			name:      "no reported day",
+			stayStart: "2021-05-01",
+			stayDays:  2,
+			failures:  2,
+			score:     0,
+		},
+		{
+			name:      "days outside the stay are ignored",
+			stayStart: "2021-05-01",
+			stayDays:  2,
+			days:      []*BreakfastDay{day("2021-04-30", "06:30", "10:00"), day("2021-05-01", "06:30", "10:00"), day("2021-05-03", "06:30", "10:00")},
+			satisfied: true,
+			failures:  1,
+			score:     0.5,
+		},
+		{
+			name:      "a day is counted once",
+			stayStart: "2021-05-01",
+			stayDays:  3,
+			days:      []*BreakfastDay{day("2021-05-01", "06:30", "10:00"), day("2021-05-01", "06:30", "10:00")},
+			failures:  2,
+			score:     float32(1) / 3,
+		},
+		{
+			name:     "missing stay start date",
+			stayDays: 2,
+			days:     []*BreakfastDay{day("2021-05-01", "06:30", "10:00")},
+			wantErr:  true,
+		},
+		{
+			name:      "missing stay days",
+			stayStart: "2021-05-01",
+			days:      []*BreakfastDay{day("2021-05-01", "06:30", "10:00")},
+			wantErr:   true,
+		},
+	}
+
+	s := &EvaluationsContract{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := &EvaluationData{
+				Code:          AgreementItemCodeServiceBreakfast,
+				StayStartDate: tt.stayStart,
+				StayDays:      tt.stayDays,
+				BreakfastDays: tt.days,
+			}
+			eResult, err := s.VerifyBreakfastAgreement(nil, agreement, data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if eResult.Satisfied != tt.satisfied {
+				t.Errorf("satisfied = %v, want %v", eResult.Satisfied, tt.satisfied)
+			}
+			if eResult.Failures != tt.failures {
+				t.Errorf("failures = %d, want %d", eResult.Failures, tt.failures)
+			}
+			if !floatEqual(eResult.Score, tt.score) {
+				t.Errorf("score = %v, want %v", eResult.Score, tt.score)
+			}
+		})
+	}
+}
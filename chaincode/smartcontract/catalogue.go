@@ -0,0 +1,414 @@
+package smartcontract
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Catalogue keys
+const (
+	catalogueCategoryObjectType = "catalogueCategory"
+	catalogueItemObjectType     = "catalogueItem"
+)
+
+// defaultCatalogueCategories are the agreement categories built in the chaincode.
+var defaultCatalogueCategories = map[string]string{
+	AgreementCategoryView:       "View",
+	AgreementCategoryService:    "Service",
+	AgreementCategoryInterior:   "Interior",
+	AgreementCategoryRoomDesign: "Room design",
+	AgreementCategoryOutdoor:    "Outdoor",
+	AgreementCategoryBed:        "Bed",
+	AgreementCategoryCustom:     "Custom",
+}
+
+// defaultCatalogueItems are the agreement item codes built in the chaincode.
+var defaultCatalogueItems = []*CatalogueItem{
+	{Code: AgreementItemCodeViewSea, Category: AgreementCategoryView, Name: "Sea view", Rank: ViewLevelMapping[AgreementItemCodeViewSea]},
+	{Code: AgreementItemCodeViewRiver, Category: AgreementCategoryView, Name: "River view", Rank: ViewLevelMapping[AgreementItemCodeViewRiver]},
+	{Code: AgreementItemCodeViewPool, Category: AgreementCategoryView, Name: "Pool view", Rank: ViewLevelMapping[AgreementItemCodeViewPool]},
+	{Code: AgreementItemCodeViewGarden, Category: AgreementCategoryView, Name: "Garden view", Rank: ViewLevelMapping[AgreementItemCodeViewGarden]},
+	{Code: AgreementItemCodeViewCity, Category: AgreementCategoryView, Name: "City view", Rank: ViewLevelMapping[AgreementItemCodeViewCity]},
+	{Code: AgreementItemCodeViewMountain, Category: AgreementCategoryView, Name: "Mountain view", Rank: ViewLevelMapping[AgreementItemCodeViewMountain]},
+
+	{Code: AgreementItemCodeBedTwin, Category: AgreementCategoryBed, Name: "Twin bed", Rank: BedPointMapping[AgreementItemCodeBedTwin]},
+	{Code: AgreementItemCodeBedQueen, Category: AgreementCategoryBed, Name: "Queen bed", Rank: BedPointMapping[AgreementItemCodeBedQueen]},
+	{Code: AgreementItemCodeBedKing, Category: AgreementCategoryBed, Name: "King bed", Rank: BedPointMapping[AgreementItemCodeBedKing]},
+	{Code: AgreementItemCodeBedDouble, Category: AgreementCategoryBed, Name: "Double bed", Rank: BedPointMapping[AgreementItemCodeBedDouble]},
+	{Code: AgreementItemCodeBedSofaBed, Category: AgreementCategoryBed, Name: "Sofa bed", Rank: BedPointMapping[AgreementItemCodeBedSofaBed]},
+	{Code: AgreementItemCodeBedBunk, Category: AgreementCategoryBed, Name: "Bunk bed", Rank: BedPointMapping[AgreementItemCodeBedBunk]},
+	{Code: AgreementItemCodeBedCrib, Category: AgreementCategoryBed, Name: "Crib", Rank: BedPointMapping[AgreementItemCodeBedCrib]},
+
+	{Code: AgreementItemCodeInteriorBathtub, Category: AgreementCategoryInterior, Name: "Bathtub"},
+	{Code: AgreementItemCodeInteriorFlatScreenTV, Category: AgreementCategoryInterior, Name: "Flat-screen TV"},
+
+	{Code: AgreementItemCodeServiceSauna, Category: AgreementCategoryService, Name: "Sauna"},
+	{Code: AgreementItemCodeServiceAirportShuttle, Category: AgreementCategoryService, Name: "Airport shuttle"},
+	{Code: AgreementItemCodeServiceBreakfast, Category: AgreementCategoryService, Name: "Breakfast"},
+	{Code: AgreementItemCodeServiceHousekeeping, Category: AgreementCategoryService, Name: "Daily housekeeping"},
+	{Code: AgreementItemCodeServiceWifi, Category: AgreementCategoryService, Name: "Wi-Fi"},
+	{Code: AgreementItemCodeServiceCheckIn, Category: AgreementCategoryService, Name: "Check-in"},
+
+	{Code: AgreementItemCodeOutdoorPatio, Category: AgreementCategoryOutdoor, Name: "Patio"},
+	{Code: AgreementItemCodeOutdoorBalcony, Category: AgreementCategoryOutdoor, Name: "Balcony"},
+
+	{Code: AgreementItemCodeRoomDesignSize, Category: AgreementCategoryRoomDesign, Name: "Room size"},
+	{Code: AgreementItemCodeRoomDesignCeilingHeight, Category: AgreementCategoryRoomDesign, Name: "Ceiling height"},
+	{Code: AgreementItemCodeRoomDesignSuiteRooms, Category: AgreementCategoryRoomDesign, Name: "Rooms in suite"},
+}
+
+// catalogue is the set of agreement categories and item codes used to validate and verify agreements.
+type catalogue struct {
+	categories map[string]*CatalogueCategory
+	items      map[string]*CatalogueItem
+
+	// onLedger is false for the catalogue built in the chaincode
+	onLedger bool
+}
+
+// defaultCatalogue returns the catalogue built in the chaincode.
+func defaultCatalogue() *catalogue {
+	c := &catalogue{
+		categories: make(map[string]*CatalogueCategory),
+		items:      make(map[string]*CatalogueItem),
+	}
+	for category, name := range defaultCatalogueCategories {
+		c.categories[category] = &CatalogueCategory{DocType: "CatalogueCategory", Category: category, Name: name}
+	}
+	for _, item := range defaultCatalogueItems {
+		catalogueItem := *item
+		catalogueItem.DocType = "CatalogueItem"
+		c.items[item.Code] = &catalogueItem
+	}
+
+	return c
+}
+
+// loadCatalogue returns the catalogue maintained in the world state, or the catalogue built in
+// the chaincode when the world state has no catalogue yet.
+func loadCatalogue(ctx contractapi.TransactionContextInterface) (*catalogue, error) {
+	c := &catalogue{
+		categories: make(map[string]*CatalogueCategory),
+		items:      make(map[string]*CatalogueItem),
+	}
+
+	categoryResultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(catalogueCategoryObjectType, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read catalogue from world state: %v", err)
+	}
+	defer categoryResultsIterator.Close()
+
+	for categoryResultsIterator.HasNext() {
+		queryResponse, err := categoryResultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var category CatalogueCategory
+		err = json.Unmarshal(queryResponse.Value, &category)
+		if err != nil {
+			return nil, err
+		}
+		c.categories[category.Category] = &category
+	}
+
+	if len(c.categories) == 0 {
+		return defaultCatalogue(), nil
+	}
+	c.onLedger = true
+
+	itemResultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(catalogueItemObjectType, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read catalogue from world state: %v", err)
+	}
+	defer itemResultsIterator.Close()
+
+	for itemResultsIterator.HasNext() {
+		queryResponse, err := itemResultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var item CatalogueItem
+		err = json.Unmarshal(queryResponse.Value, &item)
+		if err != nil {
+			return nil, err
+		}
+		c.items[item.Code] = &item
+	}
+
+	return c, nil
+}
+
+// hasCategory returns true when the catalogue has the category.
+func (c *catalogue) hasCategory(category string) bool {
+	_, ok := c.categories[category]
+	return ok
+}
+
+// hasItem returns true when the catalogue has the item code in the category.
+func (c *catalogue) hasItem(category, code string) bool {
+	item, ok := c.items[code]
+	return ok && item.Category == category
+}
+
+// ranks maps item codes of a category to their ranking values.
+func (c *catalogue) ranks(category string) map[string]int {
+	ranks := make(map[string]int)
+	for code, item := range c.items {
+		if item.Category == category {
+			ranks[code] = item.Rank
+		}
+	}
+
+	return ranks
+}
+
+// seedCatalogue writes the catalogue built in the chaincode to the world state when the world
+// state has no catalogue yet, so that maintaining the catalogue starts from the built-in codes.
+func (s *AdminContract) seedCatalogue(ctx contractapi.TransactionContextInterface) (*catalogue, error) {
+	c, err := loadCatalogue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.onLedger {
+		return c, nil
+	}
+
+	for _, category := range c.categories {
+		err = s.putCatalogueEntry(ctx, catalogueCategoryObjectType, category.Category, category)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, item := range c.items {
+		err = s.putCatalogueEntry(ctx, catalogueItemObjectType, item.Code, item)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// putCatalogueEntry writes a category or a item of the catalogue to the world state.
+func (s *AdminContract) putCatalogueEntry(ctx contractapi.TransactionContextInterface, objectType, id string, entry interface{}) error {
+	entryKey, err := ctx.GetStub().CreateCompositeKey(objectType, []string{id})
+	if err != nil {
+		return err
+	}
+
+	jEntry, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	err = ctx.GetStub().PutState(entryKey, jEntry)
+	if err != nil {
+		return fmt.Errorf("fail to save catalogue entry %s", id)
+	}
+
+	return nil
+}
+
+// deleteCatalogueEntry deletes a category or a item of the catalogue from the world state.
+func (s *AdminContract) deleteCatalogueEntry(ctx contractapi.TransactionContextInterface, objectType, id string) error {
+	entryKey, err := ctx.GetStub().CreateCompositeKey(objectType, []string{id})
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().DelState(entryKey)
+}
+
+// PutCatalogueCategory adds or renames a agreement category of the catalogue. Only an admin of the platform org can maintain the catalogue.
+func (s *AdminContract) PutCatalogueCategory(ctx contractapi.TransactionContextInterface, category, name string) (*CatalogueCategory, error) {
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = assertPlatformRole(ctx, config, RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if category == "" {
+		return nil, fmt.Errorf("the category must not be empty")
+	}
+
+	_, err = s.seedCatalogue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	catalogueCategory := &CatalogueCategory{
+		DocType:  "CatalogueCategory",
+		Category: category,
+		Name:     name,
+	}
+	err = s.putCatalogueEntry(ctx, catalogueCategoryObjectType, category, catalogueCategory)
+	if err != nil {
+		return nil, err
+	}
+
+	return catalogueCategory, nil
+}
+
+// RemoveCatalogueCategory removes a agreement category without item codes from the catalogue.
+func (s *AdminContract) RemoveCatalogueCategory(ctx contractapi.TransactionContextInterface, category string) error {
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	err = assertPlatformRole(ctx, config, RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	c, err := s.seedCatalogue(ctx)
+	if err != nil {
+		return err
+	}
+	if !c.hasCategory(category) {
+		return fmt.Errorf("the category %s does not exist", category)
+	}
+	if len(c.ranks(category)) > 0 {
+		return fmt.Errorf("the category %s still has item codes", category)
+	}
+
+	return s.deleteCatalogueEntry(ctx, catalogueCategoryObjectType, category)
+}
+
+// PutCatalogueItem adds or updates a agreement item code of the catalogue. Only an admin of the platform org can maintain the catalogue.
+func (s *AdminContract) PutCatalogueItem(ctx contractapi.TransactionContextInterface, code, category, name, parameterSchema string, rank int) (*CatalogueItem, error) {
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = assertPlatformRole(ctx, config, RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if code == "" {
+		return nil, fmt.Errorf("the item code must not be empty")
+	}
+	if parameterSchema != "" {
+		var schema map[string]interface{}
+		err = json.Unmarshal([]byte(parameterSchema), &schema)
+		if err != nil {
+			return nil, fmt.Errorf("parameter schema of item code %s is not a JSON object: %v", code, err)
+		}
+	}
+
+	c, err := s.seedCatalogue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !c.hasCategory(category) {
+		return nil, fmt.Errorf("agreement category %s has not supported yet", category)
+	}
+	// codes of the service and room design categories are verified by verifiers built in the chaincode
+	if !hasItemVerifier(category, code) {
+		return nil, fmt.Errorf("item code %s in category %s has no verifier in the chaincode", code, category)
+	}
+
+	item := &CatalogueItem{
+		DocType:         "CatalogueItem",
+		Code:            code,
+		Category:        category,
+		Name:            name,
+		ParameterSchema: parameterSchema,
+		Rank:            rank,
+	}
+	err = s.putCatalogueEntry(ctx, catalogueItemObjectType, code, item)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// RemoveCatalogueItem removes a agreement item code from the catalogue. Existing agreements keep the code.
+func (s *AdminContract) RemoveCatalogueItem(ctx contractapi.TransactionContextInterface, code string) error {
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	err = assertPlatformRole(ctx, config, RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	c, err := s.seedCatalogue(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := c.items[code]; !ok {
+		return fmt.Errorf("the item code %s does not exist", code)
+	}
+
+	return s.deleteCatalogueEntry(ctx, catalogueItemObjectType, code)
+}
+
+// ReadCatalogueItem returns the agreement item code of the catalogue.
+func (s *AdminContract) ReadCatalogueItem(ctx contractapi.TransactionContextInterface, code string) (*CatalogueItem, error) {
+	c, err := loadCatalogue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	item, ok := c.items[code]
+	if !ok {
+		return nil, fmt.Errorf("the item code %s does not exist", code)
+	}
+
+	return item, nil
+}
+
+// GetCatalogueCategories returns all agreement categories of the catalogue.
+func (s *AdminContract) GetCatalogueCategories(ctx contractapi.TransactionContextInterface) ([]*CatalogueCategory, error) {
+	c, err := loadCatalogue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make([]*CatalogueCategory, 0, len(c.categories))
+	for _, category := range c.categories {
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		return categories[i].Category < categories[j].Category
+	})
+
+	return categories, nil
+}
+
+// GetCatalogueItems returns agreement item codes of a category ordered by rank, or all item codes
+// when the category is empty.
+func (s *AdminContract) GetCatalogueItems(ctx contractapi.TransactionContextInterface, category string) ([]*CatalogueItem, error) {
+	c, err := loadCatalogue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*CatalogueItem
+	for _, item := range c.items {
+		if category == "" || item.Category == category {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Category != items[j].Category {
+			return items[i].Category < items[j].Category
+		}
+		if items[i].Rank != items[j].Rank {
+			return items[i].Rank < items[j].Rank
+		}
+		return items[i].Code < items[j].Code
+	})
+
+	return items, nil
+}
@@ -0,0 +1,124 @@
+package smartcontract
+
+import "testing"
+
+func TestPutCatalogueItem(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     string
+		code     string
+		category string
+		schema   string
+		wantErr  bool
+	}{
+		{name: "interior item", role: RoleAdmin, code: "IMB001", category: AgreementCategoryInterior},
+		{name: "bed item", role: RoleAdmin, code: "BE008", category: AgreementCategoryBed},
+		{name: "custom item with schema", role: RoleAdmin, code: "CUS001", category: AgreementCategoryCustom, schema: `{"minBandwidth":"number"}`},
+		{name: "built-in service item", role: RoleAdmin, code: AgreementItemCodeServiceWifi, category: AgreementCategoryService},
+		{name: "service item without verifier", role: RoleAdmin, code: "SSP001", category: AgreementCategoryService, wantErr: true},
+		{name: "room design item without verifier", role: RoleAdmin, code: "RD004", category: AgreementCategoryRoomDesign, wantErr: true},
+		{name: "unknown category", role: RoleAdmin, code: "IMB001", category: "spa", wantErr: true},
+		{name: "empty code", role: RoleAdmin, category: AgreementCategoryInterior, wantErr: true},
+		{name: "invalid schema", role: RoleAdmin, code: "CUS001", category: AgreementCategoryCustom, schema: "[]", wantErr: true},
+		{name: "provider", role: RoleProvider, code: "IMB001", category: AgreementCategoryInterior, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := newTestContext(t)
+			setRole(ctx, tt.role, "")
+
+			_, err := (&AdminContract{}).PutCatalogueItem(ctx, tt.code, tt.category, tt.name, tt.schema, 1)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			c, err := loadCatalogue(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !c.hasItem(tt.category, tt.code) {
+				t.Errorf("catalogue does not have item %s", tt.code)
+			}
+		})
+	}
+}
+
+func TestValidateAgreementItemsAgainstCatalogue(t *testing.T) {
+	ctx, _ := newTestContext(t)
+	setRole(ctx, RoleAdmin, "")
+	s := &AdminContract{}
+
+	_, err := s.PutCatalogueItem(ctx, "IMB001", AgreementCategoryInterior, "Minibar", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a service code stored on the ledger without a verifier in the chaincode
+	err = s.putCatalogueEntry(ctx, catalogueItemObjectType, "SSP001", &CatalogueItem{DocType: "CatalogueItem", Code: "SSP001", Category: AgreementCategoryService})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.RemoveCatalogueItem(ctx, AgreementItemCodeOutdoorPatio)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := loadCatalogue(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		category string
+		code     string
+		wantErr  bool
+	}{
+		{name: "added item", category: AgreementCategoryInterior, code: "IMB001"},
+		{name: "built-in item", category: AgreementCategoryInterior, code: AgreementItemCodeInteriorBathtub},
+		{name: "removed item", category: AgreementCategoryOutdoor, code: AgreementItemCodeOutdoorPatio, wantErr: true},
+		{name: "item of another category", category: AgreementCategoryOutdoor, code: "IMB001", wantErr: true},
+		{name: "service item without verifier", category: AgreementCategoryService, code: "SSP001", wantErr: true},
+		{name: "unknown category", category: "spa", code: "IMB001", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAgreementItems(c, tt.category, []*AgreementItem{{Code: tt.code}})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestItemVerifiersCoverBuiltInItems(t *testing.T) {
+	for _, item := range defaultCatalogueItems {
+		if !hasItemVerifier(item.Category, item.Code) {
+			t.Errorf("built-in item %s in category %s has no verifier", item.Code, item.Category)
+		}
+	}
+}
+
+func TestRemoveCatalogueCategory(t *testing.T) {
+	ctx, _ := newTestContext(t)
+	setRole(ctx, RoleAdmin, "")
+	s := &AdminContract{}
+
+	err := s.RemoveCatalogueCategory(ctx, AgreementCategoryOutdoor)
+	if err == nil {
+		t.Errorf("category %s with item codes is removed", AgreementCategoryOutdoor)
+	}
+
+	_, err = s.PutCatalogueCategory(ctx, "spa", "Spa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.RemoveCatalogueCategory(ctx, "spa")
+	if err != nil {
+		t.Errorf("category without item codes is not removed: %v", err)
+	}
+}
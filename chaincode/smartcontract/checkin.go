@@ -0,0 +1,62 @@
+package smartcontract
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// validateCheckInItem validates the wait times of a check-in item.
+func validateCheckInItem(item *AgreementItem) error {
+	if item.CheckInShortWaitTime <= 0 {
+		return fmt.Errorf("check-in short wait time of agreement item %s must be positive", item.Code)
+	}
+	if item.CheckInLongWaitTime < item.CheckInShortWaitTime {
+		return fmt.Errorf("check-in long wait time of agreement item %s must not be less than the short wait time", item.Code)
+	}
+
+	return nil
+}
+
+// VerifyCheckInAgreement verify front-desk check-in agreement. The guest waits from arriving at the
+// front desk until being served. A wait within the short wait time is satisfied, a wait within the
+// long wait time is a minor failure and a longer wait is a major failure.
+func (s *EvaluationsContract) VerifyCheckInAgreement(_ contractapi.TransactionContextInterface, a *Agreement, data *EvaluationData) (*EvaluationResult, error) {
+	p, ok := payloadOf(AgreementCategoryService, data).(*checkInPayload)
+	if !ok {
+		return nil, fmt.Errorf("evaluation data code %s is not check-in service item code", data.Code)
+	}
+
+	return verifyCheckInAgreement(a, p)
+}
+
+// verifyCheckInAgreement verifies check-in agreement against the check-in payload.
+func verifyCheckInAgreement(a *Agreement, data *checkInPayload) (*EvaluationResult, error) {
+	if data.ArriveAtFrontDeskAt.IsZero() || data.CheckInServedAt.IsZero() {
+		return nil, fmt.Errorf("front desk arrival and check-in service times are required")
+	}
+
+	agreementItem := a.Items[0]
+	wait := nonNegativeMinutes(data.CheckInServedAt.Sub(data.ArriveAtFrontDeskAt))
+	if wait <= float64(agreementItem.CheckInShortWaitTime) {
+		return &EvaluationResult{
+			Satisfied: true,
+			Score:     1,
+		}, nil
+	}
+
+	eResult := &EvaluationResult{
+		Satisfied:     false,
+		PenaltyRules:  defaultPenaltyRules(a, 1),
+		FailureReason: fmt.Sprintf("the guest waited %.0f minutes to check in", wait),
+		Severity:      PenaltySeverityMinor,
+		DelayMinutes:  wait,
+		Score:         delayScore(wait, agreementItem.CheckInShortWaitTime, agreementItem.CheckInLongWaitTime),
+	}
+	if wait > float64(agreementItem.CheckInLongWaitTime) {
+		eResult.PenaltyRules = defaultPenaltyRules(a, 2)
+		eResult.Severity = PenaltySeverityMajor
+	}
+
+	return eResult, nil
+}
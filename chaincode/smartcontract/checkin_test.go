@@ -0,0 +1,112 @@
+package smartcontract
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerifyCheckInAgreement(t *testing.T) {
+	arriveAt := time.Date(2021, 5, 1, 14, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time {
+		return arriveAt.Add(time.Duration(minutes) * time.Minute)
+	}
+
+	agreement := &Agreement{
+		Category: AgreementCategoryService,
+		Items: []*AgreementItem{{
+			Code:                 AgreementItemCodeServiceCheckIn,
+			CheckInShortWaitTime: 10,
+			CheckInLongWaitTime:  30,
+		}},
+		HasPenaltyRule: true,
+		PenaltyRules: []*PenaltyRule{
+			{Type: PenaltyRuleTypeDiscount, DiscountPercent: 100},
+			{Type: PenaltyRuleTypeDiscount, DiscountPercent: 10},
+			{Type: PenaltyRuleTypeDiscount, DiscountPercent: 30},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		arriveAt  time.Time
+		servedAt  time.Time
+		wantErr   bool
+		satisfied bool
+		severity  string
+		delay     float64
+		score     float32
+		discount  float32
+	}{
+		{
+			name:      "served within the short wait",
+			arriveAt:  arriveAt,
+			servedAt:  at(10),
+			satisfied: true,
+			score:     1,
+		},
+		{
+			name:      "served before arriving",
+			arriveAt:  arriveAt,
+			servedAt:  at(-5),
+			satisfied: true,
+			score:     1,
+		},
+		{
+			name:     "served within the long wait",
+			arriveAt: arriveAt,
+			servedAt: at(20),
+			severity: PenaltySeverityMinor,
+			delay:    20,
+			score:    0.75,
+			discount: 10,
+		},
+		{
+			name:     "served after the long wait",
+			arriveAt: arriveAt,
+			servedAt: at(45),
+			severity: PenaltySeverityMajor,
+			delay:    45,
+			score:    0.25,
+			discount: 30,
+		},
+		{
+			name:     "missing service time",
+			arriveAt: arriveAt,
+			wantErr:  true,
+		},
+	}
+
+	s := &EvaluationsContract{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := &EvaluationData{Code: AgreementItemCodeServiceCheckIn, ArriveAtFrontDeskAt: tt.arriveAt, CheckInServedAt: tt.servedAt}
+			eResult, err := s.VerifyCheckInAgreement(nil, agreement, data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if eResult.Satisfied != tt.satisfied {
+				t.Errorf("satisfied = %v, want %v", eResult.Satisfied, tt.satisfied)
+			}
+			if eResult.Severity != tt.severity {
+				t.Errorf("severity = %q, want %q", eResult.Severity, tt.severity)
+			}
+			if eResult.DelayMinutes != tt.delay {
+				t.Errorf("delay = %v, want %v", eResult.DelayMinutes, tt.delay)
+			}
+			if !floatEqual(eResult.Score, tt.score) {
+				t.Errorf("score = %v, want %v", eResult.Score, tt.score)
+			}
+			var discount float32
+			for _, rule := range eResult.PenaltyRules {
+				discount += rule.DiscountPercent
+			}
+			if discount != tt.discount {
+				t.Errorf("discount = %v, want %v", discount, tt.discount)
+			}
+		})
+	}
+}
@@ -0,0 +1,318 @@
+package smartcontract
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Compensation keys
+const (
+	compensationObjectType = "compensation"
+	compensationIndex      = "service~agreement~compensation"
+)
+
+// createCompensation records a pending compensation for a triggered penalty rule.
+// A compensation is identified by the evaluation which triggered it.
+func (s *EvaluationsContract) createCompensation(ctx contractapi.TransactionContextInterface, config *ContractConfig, sid, aid, eid, rid string, eResult *EvaluationResult, at string) (*Compensation, error) {
+	exist, err := s.CompensationExists(ctx, eid)
+	if err != nil {
+		return nil, err
+	}
+	if exist {
+		return nil, fmt.Errorf("the compensation %s already exists", eid)
+	}
+
+	createdAt, err := ParseTime(at)
+	if err != nil {
+		return nil, fmt.Errorf("can not parse evaluation time %s: %v", at, err)
+	}
+
+	// the shortest due time of the triggered penalty rules applies
+	dueHours := 0
+	for _, rule := range eResult.PenaltyRules {
+		if rule.DueHours > 0 && (dueHours == 0 || rule.DueHours < dueHours) {
+			dueHours = rule.DueHours
+		}
+	}
+	if dueHours == 0 {
+		dueHours = config.CompensationDueHours
+	}
+
+	compensation := &Compensation{
+		DocType:         "Compensation",
+		CompensationID:  eid,
+		ServiceID:       sid,
+		AgreementID:     aid,
+		EvaluationID:    eid,
+		ReservationID:   rid,
+		PenaltyRules:    eResult.PenaltyRules,
+		PenaltyRule:     firstPenaltyRule(eResult.PenaltyRules),
+		Amount:          eResult.Amount,
+		DiscountPercent: eResult.DiscountPercent,
+		UpgradeTarget:   eResult.UpgradeTarget,
+		Status:          CompensationStatusPending,
+		CreatedAt:       at,
+		DueAt:           createdAt.Add(time.Duration(dueHours) * time.Hour).Format(RFC3339),
+		TxID:            ctx.GetStub().GetTxID(),
+	}
+
+	err = s.putCompensation(ctx, compensation)
+	if err != nil {
+		return nil, err
+	}
+
+	docCompensationIndexKey, err := ctx.GetStub().CreateCompositeKey(compensationIndex, []string{sid, aid, eid})
+	if err != nil {
+		return nil, err
+	}
+	//  Save compensationIndex entry to world state. Only the key name is needed, no need to store a duplicate copy of the compensation.
+	//  Note - passing a 'nil' value will effectively delete the key from state, therefore we pass null character as value
+	value := []byte{0x00}
+	err = ctx.GetStub().PutState(docCompensationIndexKey, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return compensation, nil
+}
+
+// putCompensation writes the compensation to the world state.
+func (s *EvaluationsContract) putCompensation(ctx contractapi.TransactionContextInterface, compensation *Compensation) error {
+	compensationKey, err := ctx.GetStub().CreateCompositeKey(compensationObjectType, []string{compensation.CompensationID})
+	if err != nil {
+		return err
+	}
+
+	jCompensation, err := json.Marshal(compensation)
+	if err != nil {
+		return err
+	}
+
+	err = ctx.GetStub().PutState(compensationKey, jCompensation)
+	if err != nil {
+		return fmt.Errorf("fail to save compensation %s", compensation.CompensationID)
+	}
+
+	return nil
+}
+
+// CompensationExists returns true when compensation with given id exists in world state.
+func (s *EvaluationsContract) CompensationExists(ctx contractapi.TransactionContextInterface, cid string) (bool, error) {
+	compensationKey, err := ctx.GetStub().CreateCompositeKey(compensationObjectType, []string{cid})
+	if err != nil {
+		return false, err
+	}
+
+	exist, err := ctx.GetStub().GetState(compensationKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to read compensation from world state: %v", err)
+	}
+
+	return exist != nil, nil
+}
+
+// ReadCompensation returns the compensation stored in the world state with given id.
+func (s *EvaluationsContract) ReadCompensation(ctx contractapi.TransactionContextInterface, cid string) (*Compensation, error) {
+	compensationKey, err := ctx.GetStub().CreateCompositeKey(compensationObjectType, []string{cid})
+	if err != nil {
+		return nil, err
+	}
+
+	jCompensation, err := ctx.GetStub().GetState(compensationKey)
+	if err != nil {
+		return nil, err
+	}
+	if jCompensation == nil {
+		return nil, fmt.Errorf("the compensation %s does not exist", cid)
+	}
+
+	var compensation Compensation
+	err = json.Unmarshal(jCompensation, &compensation)
+	if err != nil {
+		return nil, err
+	}
+
+	return &compensation, nil
+}
+
+// GetCompensationsByAgreement returns all compensations of a agreement of a service.
+func (s *EvaluationsContract) GetCompensationsByAgreement(ctx contractapi.TransactionContextInterface, sid, aid string) ([]*Compensation, error) {
+	compensationResultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(compensationIndex, []string{sid, aid})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+
+	defer compensationResultsIterator.Close()
+
+	var compensations []*Compensation
+	for compensationResultsIterator.HasNext() {
+		rangeResponse, err := compensationResultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(rangeResponse.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(compositeKeyParts) > 2 {
+			compensation, err := s.ReadCompensation(ctx, compositeKeyParts[2])
+			if err != nil {
+				return nil, err
+			}
+			compensations = append(compensations, compensation)
+		}
+	}
+
+	return compensations, nil
+}
+
+// SettleCompensation settles a pending compensation as paid or waived with the evidence hash at the
+// time of the transaction. Only the provider of the service can mark it paid and only an arbitrator
+// or an admin of the platform org can waive it. A compensation settled after its due time is counted
+// as a rule violation without compensation.
+func (s *EvaluationsContract) SettleCompensation(ctx contractapi.TransactionContextInterface, cid, status, evidenceHash string) (*Compensation, error) {
+	compensation, err := s.ReadCompensation(ctx, cid)
+	if err != nil {
+		return nil, err
+	}
+
+	recorder, err := newEvaluationRecorder(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	err = recorder.settle(ctx, compensation, status, evidenceHash)
+	if err != nil {
+		return nil, err
+	}
+
+	err = recorder.save(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return compensation, nil
+}
+
+// ProcessOverdueCompensations counts pending compensations of a service which passed their due time
+// at the time of the transaction as rule violations without compensation. Only an admin of the platform org can process them.
+func (s *EvaluationsContract) ProcessOverdueCompensations(ctx contractapi.TransactionContextInterface, sid string) (*Service, error) {
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = assertPlatformRole(ctx, config, RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := readService(ctx, sid)
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	at := now.Format(RFC3339)
+
+	for _, agreement := range service.Agreements {
+		compensations, err := s.GetCompensationsByAgreement(ctx, sid, agreement.AgreementID)
+		if err != nil {
+			return nil, err
+		}
+
+		changed := false
+		for _, compensation := range compensations {
+			if compensation.Status != CompensationStatusPending || compensation.Overdue {
+				continue
+			}
+
+			dueAt, err := ParseTime(compensation.DueAt)
+			if err != nil {
+				return nil, err
+			}
+			if !now.After(dueAt) {
+				continue
+			}
+
+			compensation.Overdue = true
+			compensation.TxID = ctx.GetStub().GetTxID()
+			err = s.putCompensation(ctx, compensation)
+			if err != nil {
+				return nil, err
+			}
+			changed = true
+		}
+
+		if changed {
+			countRuleViolations(agreement, compensations)
+			refreshRuleAbidingRate(service, agreement)
+			agreement.LastEvaluationAt = at
+			service.LastEvaluationAt = at
+		}
+	}
+
+	jService, err := json.Marshal(service)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().PutState(sid, jService)
+	if err != nil {
+		return nil, fmt.Errorf("fail to update rule-abiding rate for service %s", sid)
+	}
+
+	return service, nil
+}
+
+// refreshRuleAbidingCounts recounts the rule violations of the agreement from it's compensations and
+// refreshes the rule-abiding rates. The world state does not reflect writes of the transaction, so
+// compensations changed by the transaction are given to replace the stored ones.
+func (s *EvaluationsContract) refreshRuleAbidingCounts(ctx contractapi.TransactionContextInterface, service *Service, agreement *Agreement, changed ...*Compensation) error {
+	compensations, err := s.GetCompensationsByAgreement(ctx, service.ServiceID, agreement.AgreementID)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range changed {
+		replaced := false
+		for i, compensation := range compensations {
+			if compensation.CompensationID == c.CompensationID {
+				compensations[i] = c
+				replaced = true
+			}
+		}
+		if !replaced {
+			compensations = append(compensations, c)
+		}
+	}
+
+	countRuleViolations(agreement, compensations)
+	refreshRuleAbidingRate(service, agreement)
+
+	return nil
+}
+
+// countRuleViolations counts the rule violations of the agreement from it's compensations. A settled or
+// overdue compensation is a violation, an overdue one is a violation without compensation. Pending
+// compensations which are not due yet and canceled or refunded compensations are not counted.
+func countRuleViolations(agreement *Agreement, compensations []*Compensation) {
+	agreement.TotalRuleViolations = 0
+	agreement.TotalRuleViolationWithoutCompensations = 0
+	for _, compensation := range compensations {
+		switch {
+		case compensation.Status == CompensationStatusCanceled || compensation.Status == CompensationStatusRefunded:
+		case compensation.Overdue:
+			agreement.TotalRuleViolations++
+			agreement.TotalRuleViolationWithoutCompensations++
+		case compensation.Status == CompensationStatusPaid || compensation.Status == CompensationStatusWaived:
+			agreement.TotalRuleViolations++
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package smartcontract
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// createTestCompensation records a compensation of the recorder service created the given hours ago,
+// it is due after the default due hours.
+func createTestCompensation(t *testing.T, ctx *contractapi.TransactionContext, eid string, hoursAgo int) {
+	t.Helper()
+
+	at := time.Now().UTC().Add(-time.Duration(hoursAgo) * time.Hour).Format(RFC3339)
+	eResult := &EvaluationResult{
+		PenaltyRules:    []*PenaltyRule{{Type: PenaltyRuleTypeDiscount, DiscountPercent: 20}},
+		DiscountPercent: 20,
+	}
+	_, err := (&EvaluationsContract{}).createCompensation(ctx, defaultConfig(), recorderServiceID, recorderAgreementID, eid, "r1", eResult, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSettleCompensation(t *testing.T) {
+	s := &EvaluationsContract{}
+	due := DefaultCompensationDueHours
+
+	tests := []struct {
+		name     string
+		role     string
+		sid      string
+		status   string
+		hoursAgo int
+		wantErr  bool
+
+		overdue         bool
+		ruleAbidingRate float32
+	}{
+		{name: "paid on time by provider", role: RoleProvider, sid: recorderServiceID, status: CompensationStatusPaid, hoursAgo: 1, ruleAbidingRate: 1},
+		{name: "paid late by provider", role: RoleProvider, sid: recorderServiceID, status: CompensationStatusPaid, hoursAgo: due + 1, overdue: true},
+		{name: "waived by arbitrator", role: RoleArbitrator, status: CompensationStatusWaived, hoursAgo: 1, ruleAbidingRate: 1},
+		{name: "waived by admin", role: RoleAdmin, status: CompensationStatusWaived, hoursAgo: 1, ruleAbidingRate: 1},
+		{name: "waived by provider", role: RoleProvider, sid: recorderServiceID, status: CompensationStatusWaived, hoursAgo: 1, wantErr: true},
+		{name: "paid by provider of another service", role: RoleProvider, sid: "service2", status: CompensationStatusPaid, hoursAgo: 1, wantErr: true},
+		{name: "paid by arbitrator", role: RoleArbitrator, status: CompensationStatusPaid, hoursAgo: 1, wantErr: true},
+		{name: "settled as pending", role: RoleAdmin, status: CompensationStatusPending, hoursAgo: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, stub := newTestContext(t)
+			putTestService(t, stub, shuttleService(recorderServiceID))
+			createTestCompensation(t, ctx, "e1", tt.hoursAgo)
+
+			setRole(ctx, tt.role, tt.sid)
+			compensation, err := s.SettleCompensation(ctx, "e1", tt.status, "evidence")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if compensation.Status != tt.status || compensation.Overdue != tt.overdue {
+				t.Errorf("compensation = %s/%v, want %s/%v", compensation.Status, compensation.Overdue, tt.status, tt.overdue)
+			}
+
+			service, err := readService(ctx, recorderServiceID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			agreement := service.Agreements[0]
+			if agreement.TotalRuleViolations != 1 {
+				t.Errorf("rule violations = %d, want 1", agreement.TotalRuleViolations)
+			}
+			if service.RuleAbidingRate != tt.ruleAbidingRate {
+				t.Errorf("rule-abiding rate = %v, want %v", service.RuleAbidingRate, tt.ruleAbidingRate)
+			}
+
+			_, err = s.SettleCompensation(ctx, "e1", tt.status, "evidence")
+			if err == nil {
+				t.Error("a settled compensation is settled again")
+			}
+		})
+	}
+}
+
+func TestProcessOverdueCompensations(t *testing.T) {
+	s := &EvaluationsContract{}
+	ctx, stub := newTestContext(t)
+	putTestService(t, stub, shuttleService(recorderServiceID))
+	createTestCompensation(t, ctx, "e1", DefaultCompensationDueHours+1)
+	createTestCompensation(t, ctx, "e2", 1)
+
+	setRole(ctx, RoleProvider, recorderServiceID)
+	_, err := s.ProcessOverdueCompensations(ctx, recorderServiceID)
+	if err == nil {
+		t.Fatal("a provider processed overdue compensations")
+	}
+
+	assertViolations := func(violations, uncompensated uint, rate float32) {
+		t.Helper()
+
+		service, err := readService(ctx, recorderServiceID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		agreement := service.Agreements[0]
+		if agreement.TotalRuleViolations != violations || agreement.TotalRuleViolationWithoutCompensations != uncompensated {
+			t.Errorf("rule violations = %d/%d, want %d/%d", agreement.TotalRuleViolations, agreement.TotalRuleViolationWithoutCompensations, violations, uncompensated)
+		}
+		if service.RuleAbidingRate != rate {
+			t.Errorf("rule-abiding rate = %v, want %v", service.RuleAbidingRate, rate)
+		}
+	}
+
+	setRole(ctx, RoleAdmin, "")
+	_, err = s.ProcessOverdueCompensations(ctx, recorderServiceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertViolations(1, 1, 0)
+
+	// processing again does not count the overdue compensation twice
+	_, err = s.ProcessOverdueCompensations(ctx, recorderServiceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertViolations(1, 1, 0)
+
+	// paying the overdue compensation late does not count it twice either
+	setRole(ctx, RoleProvider, recorderServiceID)
+	_, err = s.SettleCompensation(ctx, "e1", CompensationStatusPaid, "evidence")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertViolations(1, 1, 0)
+
+	_, err = s.SettleCompensation(ctx, "e2", CompensationStatusPaid, "evidence")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertViolations(2, 1, 0.5)
+}
+
+func TestUpdateRuleAbidingRate(t *testing.T) {
+	s := &EvaluationsContract{}
+
+	tests := []struct {
+		name        string
+		role        string
+		owner       string
+		sid         string
+		aid         string
+		compensated bool
+		wantErr     bool
+		wantStatus  string
+	}{
+		{name: "compensated by provider", role: RoleProvider, owner: recorderServiceID, sid: recorderServiceID, aid: recorderAgreementID, compensated: true, wantStatus: CompensationStatusPaid},
+		{name: "not compensated by arbitrator", role: RoleArbitrator, sid: recorderServiceID, aid: recorderAgreementID, wantStatus: CompensationStatusWaived},
+		{name: "not compensated by provider", role: RoleProvider, owner: recorderServiceID, sid: recorderServiceID, aid: recorderAgreementID, wantErr: true},
+		{name: "compensated by provider of another service", role: RoleProvider, owner: "service2", sid: recorderServiceID, aid: recorderAgreementID, compensated: true, wantErr: true},
+		{name: "another agreement", role: RoleProvider, owner: recorderServiceID, sid: recorderServiceID, aid: "agreement2", compensated: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, handle := range []func(contractapi.TransactionContextInterface, string, string, string, string, string, bool) (*Service, error){
+				s.UpdateRuleAbidingRate,
+				s.HandlePenaltyRuleEvaluationEvent,
+			} {
+				ctx, stub := newTestContext(t)
+				putTestService(t, stub, shuttleService(recorderServiceID))
+				createTestCompensation(t, ctx, "e1", 1)
+				setRole(ctx, tt.role, tt.owner)
+
+				service, err := handle(ctx, tt.sid, tt.aid, "e1", "evidence", recorderAt, tt.compensated)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+				}
+				if tt.wantErr {
+					continue
+				}
+
+				compensation, err := s.ReadCompensation(ctx, "e1")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if compensation.Status != tt.wantStatus || compensation.EvidenceHash != "evidence" {
+					t.Errorf("compensation = %s/%s, want %s/evidence", compensation.Status, compensation.EvidenceHash, tt.wantStatus)
+				}
+				// a violation settled through the compensation is counted once
+				if service.Agreements[0].TotalRuleViolations != 1 || service.RuleAbidingRate != 1 {
+					t.Errorf("rule violations = %d, rate = %v, want 1 violation and rate 1", service.Agreements[0].TotalRuleViolations, service.RuleAbidingRate)
+				}
+			}
+		})
+	}
+}
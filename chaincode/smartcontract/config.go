@@ -0,0 +1,160 @@
+package smartcontract
+
+import (
+	b64 "encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Config keys
+const (
+	contractConfigKey = "contract_config"
+)
+
+// Config events
+const (
+	EventConfigUpdated = "ConfigUpdated"
+)
+
+// defaultConfig returns the configuration built in the chaincode.
+func defaultConfig() *ContractConfig {
+	return &ContractConfig{
+		DocType:                      "ContractConfig",
+		PenaltyEndpoint:              DefaultPenaltyEndpoint,
+		PenaltyRequestTimeoutSeconds: DefaultPenaltyRequestTimeoutSeconds,
+		RateStrategy:                 RateStrategyCount,
+		ItemPolicy:                   ItemPolicyAll,
+		ItemPassThreshold:            DefaultItemPassThreshold,
+		CompensationDueHours:         DefaultCompensationDueHours,
+		PenaltyEnforcementEnabled:    true,
+		CompensationsEnabled:         true,
+		PlatformMSPID:                DefaultPlatformMSPID,
+	}
+}
+
+// loadConfig returns the configuration on the ledger, or the configuration built in the chaincode
+// when the ledger has not been initialized.
+func loadConfig(ctx contractapi.TransactionContextInterface) (*ContractConfig, error) {
+	jConfig, err := ctx.GetStub().GetState(contractConfigKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration from world state: %v", err)
+	}
+	if jConfig == nil {
+		return defaultConfig(), nil
+	}
+
+	var config ContractConfig
+	err = json.Unmarshal(jConfig, &config)
+	if err != nil {
+		return nil, err
+	}
+	// configurations stored before the platform org was configurable
+	if config.PlatformMSPID == "" {
+		config.PlatformMSPID = DefaultPlatformMSPID
+	}
+
+	return &config, nil
+}
+
+// validateConfig validates a configuration.
+func validateConfig(config *ContractConfig) error {
+	endpoint, err := url.Parse(config.PenaltyEndpoint)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return fmt.Errorf("penalty endpoint %s is not a http url", config.PenaltyEndpoint)
+	}
+	if config.PenaltyRequestTimeoutSeconds <= 0 {
+		return fmt.Errorf("penalty request timeout must be positive")
+	}
+	if config.RateStrategy != RateStrategyCount && config.RateStrategy != RateStrategyScore {
+		return fmt.Errorf("satisfaction rate strategy %s has not supported yet", config.RateStrategy)
+	}
+	if config.ItemPolicy != ItemPolicyAll && config.ItemPolicy != ItemPolicyMajority && config.ItemPolicy != ItemPolicyWeighted {
+		return fmt.Errorf("item policy %s has not supported yet", config.ItemPolicy)
+	}
+	if config.ItemPassThreshold <= 0 || config.ItemPassThreshold > 1 {
+		return fmt.Errorf("item pass threshold must be greater than 0 and at most 1")
+	}
+	if config.RateWindowSize < 0 {
+		return fmt.Errorf("rate window size must not be negative")
+	}
+	if config.CompensationDueHours <= 0 {
+		return fmt.Errorf("compensation due hours must be positive")
+	}
+	if config.PlatformMSPID == "" {
+		return fmt.Errorf("the platform MSP ID must not be empty")
+	}
+	return nil
+}
+
+// putConfig validates and writes the configuration to the world state with the next version.
+func putConfig(ctx contractapi.TransactionContextInterface, config *ContractConfig, version int, at string) error {
+	err := validateConfig(config)
+	if err != nil {
+		return err
+	}
+
+	updatedBy, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return err
+	}
+
+	config.DocType = "ContractConfig"
+	config.Version = version
+	config.UpdatedBy = updatedBy
+	config.UpdatedAt = at
+	config.TxID = ctx.GetStub().GetTxID()
+
+	jConfig, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(contractConfigKey, jConfig)
+	if err != nil {
+		return fmt.Errorf("fail to save configuration")
+	}
+
+	return ctx.GetStub().SetEvent(EventConfigUpdated, jConfig)
+}
+
+// UpdateConfig replaces the configuration with the given base64 encoded configuration at the time of
+// the transaction. Only an admin of the platform org can update the configuration.
+func (s *AdminContract) UpdateConfig(ctx contractapi.TransactionContextInterface, config string) (*ContractConfig, error) {
+	current, err := loadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = assertPlatformRole(ctx, current, RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	dConfig, err := b64.StdEncoding.DecodeString(config)
+	if err != nil {
+		return nil, fmt.Errorf("can not decode configuration from base64: %v", err)
+	}
+	var cConfig ContractConfig
+	err = json.Unmarshal(dConfig, &cConfig)
+	if err != nil {
+		return nil, fmt.Errorf("can not unmarshal configuration: %v", err)
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = putConfig(ctx, &cConfig, current.Version+1, now.Format(RFC3339))
+	if err != nil {
+		return nil, err
+	}
+
+	return &cConfig, nil
+}
+
+// ReadConfig returns the configuration in effect.
+func (s *AdminContract) ReadConfig(ctx contractapi.TransactionContextInterface) (*ContractConfig, error) {
+	return loadConfig(ctx)
+}
@@ -0,0 +1,127 @@
+package smartcontract
+
+import (
+	b64 "encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestLoadConfig(t *testing.T) {
+	stub := shimtest.NewMockStub("tourism", nil)
+	ctx := &contractapi.TransactionContext{}
+	ctx.SetStub(stub)
+
+	config, err := loadConfig(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *config != *defaultConfig() {
+		t.Errorf("config = %+v, want the default configuration", config)
+	}
+	if err := validateConfig(config); err != nil {
+		t.Errorf("the default configuration is not valid: %v", err)
+	}
+}
+
+func TestUpdateConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		role        string
+		clientMSPID string
+		update      func(config *ContractConfig)
+		wantErr     bool
+	}{
+		{name: "default configuration", role: RoleAdmin, update: func(config *ContractConfig) {}},
+		{name: "score strategy and weighted items", role: RoleAdmin, update: func(config *ContractConfig) {
+			config.RateStrategy = RateStrategyScore
+			config.ItemPolicy = ItemPolicyWeighted
+			config.ItemPassThreshold = 0.8
+			config.RateWindowSize = 20
+		}},
+		{name: "https endpoint", role: RoleAdmin, update: func(config *ContractConfig) { config.PenaltyEndpoint = "https://example.com/penalties" }},
+		{name: "provider", role: RoleProvider, update: func(config *ContractConfig) {}, wantErr: true},
+		{name: "admin of another org", role: RoleAdmin, clientMSPID: "Org2MSP", update: func(config *ContractConfig) {}, wantErr: true},
+		{name: "arbitrator", role: RoleArbitrator, update: func(config *ContractConfig) {}, wantErr: true},
+		{name: "endpoint without scheme", role: RoleAdmin, update: func(config *ContractConfig) { config.PenaltyEndpoint = "example.com/penalties" }, wantErr: true},
+		{name: "ftp endpoint", role: RoleAdmin, update: func(config *ContractConfig) { config.PenaltyEndpoint = "ftp://example.com" }, wantErr: true},
+		{name: "zero timeout", role: RoleAdmin, update: func(config *ContractConfig) { config.PenaltyRequestTimeoutSeconds = 0 }, wantErr: true},
+		{name: "unsupported rate strategy", role: RoleAdmin, update: func(config *ContractConfig) { config.RateStrategy = "median" }, wantErr: true},
+		{name: "empty item policy", role: RoleAdmin, update: func(config *ContractConfig) { config.ItemPolicy = "" }, wantErr: true},
+		{name: "zero pass threshold", role: RoleAdmin, update: func(config *ContractConfig) { config.ItemPassThreshold = 0 }, wantErr: true},
+		{name: "pass threshold over 1", role: RoleAdmin, update: func(config *ContractConfig) { config.ItemPassThreshold = 1.2 }, wantErr: true},
+		{name: "negative rate window", role: RoleAdmin, update: func(config *ContractConfig) { config.RateWindowSize = -1 }, wantErr: true},
+		{name: "zero compensation due hours", role: RoleAdmin, update: func(config *ContractConfig) { config.CompensationDueHours = 0 }, wantErr: true},
+		{name: "empty platform MSP ID", role: RoleAdmin, update: func(config *ContractConfig) { config.PlatformMSPID = "" }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, stub := newTestContext(t)
+			setTxTime(stub, time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC))
+			ctx.SetClientIdentity(&testIdentity{id: tt.role, mspID: tt.clientMSPID, attrs: map[string]string{IdentityAttributeRole: tt.role}})
+
+			current, err := loadConfig(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			config := defaultConfig()
+			tt.update(config)
+			jConfig, err := json.Marshal(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			updated, err := (&AdminContract{}).UpdateConfig(ctx, b64.StdEncoding.EncodeToString(jConfig))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+
+			stored, err := loadConfig(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr {
+				if *stored != *current {
+					t.Errorf("a rejected configuration is stored: %+v", stored)
+				}
+				return
+			}
+
+			if updated.Version != current.Version+1 {
+				t.Errorf("version = %d, want %d", updated.Version, current.Version+1)
+			}
+			if updated.UpdatedBy != tt.role || updated.UpdatedAt != "2021-06-01T08:00:00.000Z" {
+				t.Errorf("updated by %s at %s, want %s at the transaction time", updated.UpdatedBy, updated.UpdatedAt, tt.role)
+			}
+			if *stored != *updated {
+				t.Errorf("stored config = %+v, want %+v", stored, updated)
+			}
+		})
+	}
+}
+
+func TestUpdateConfigInvalidPayload(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{name: "not base64", config: "{}"},
+		{name: "not json", config: b64.StdEncoding.EncodeToString([]byte("config"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := newTestContext(t)
+			setRole(ctx, RoleAdmin, "")
+
+			_, err := (&AdminContract{}).UpdateConfig(ctx, tt.config)
+			if err == nil {
+				t.Errorf("the configuration %s is accepted", tt.config)
+			}
+		})
+	}
+}
@@ -1,8 +1,17 @@
 package smartcontract
 
 const (
-	// BaseURL base url to call to server.
-	BaseURL = "http://doan.vinaictgroup.com:8181/tourism-block/v1"
+	// ContractVersion version of the contract.
+	ContractVersion = "1.1.0"
+)
+
+// SchemaVersion is the version of the schema of services written by this chaincode.
+const SchemaVersion = 3
+
+// defaults of the contract configuration, they apply until the configuration is initialized on the ledger.
+const (
+	DefaultPenaltyEndpoint              = "http://doan.vinaictgroup.com:8181/tourism-block/v1/rpc/agreements/enforce-penalty-rules"
+	DefaultPenaltyRequestTimeoutSeconds = 60
 )
 
 // list of categories of agreement.
@@ -13,6 +22,7 @@ const (
 	AgreementCategoryRoomDesign = "room_design"
 	AgreementCategoryOutdoor    = "outdoor"
 	AgreementCategoryBed        = "bed"
+	AgreementCategoryCustom     = "custom"
 	AgreementCategoryFacility   = "facility" // TODO: deprecated
 )
 
@@ -22,6 +32,115 @@ const (
 	PenaltyRuleTypeUpgradeLevel = "upgrade_level"
 )
 
+// list of penalty severities.
+const (
+	PenaltySeverityMinor    = "minor"
+	PenaltySeverityMajor    = "major"
+	PenaltySeverityCritical = "critical"
+)
+
+// list of penalty combinations, the first applicable penalty rule is used by default.
+const (
+	PenaltyCombinationFirst      = "first"
+	PenaltyCombinationCumulative = "cumulative"
+)
+
+// list of item policies, all items must pass by default.
+const (
+	ItemPolicyAll      = "all"
+	ItemPolicyMajority = "majority"
+	ItemPolicyWeighted = "weighted"
+)
+
+// DefaultItemPassThreshold is the ratio of passed item weights required by the weighted item policy
+// when the agreement does not specify it, it seeds the configuration.
+const DefaultItemPassThreshold = 0.5
+
+// list of satisfaction rate strategies, the rate is computed from counts by default.
+const (
+	RateStrategyCount = "count"
+	RateStrategyScore = "score"
+)
+
+// list of view modes, views are matched by ranked upgrade by default.
+const (
+	ViewModeExact         = "exact"
+	ViewModeAnyOf         = "any_of"
+	ViewModeRankedUpgrade = "ranked_upgrade"
+)
+
+// PartialViewScore is the score of a matching view which is only partial when the agreement
+// does not allow partial views.
+const PartialViewScore = 0.5
+
+// list of bed match modes, beds are matched by equivalent points by default.
+const (
+	BedMatchModeExact             = "exact"
+	BedMatchModeAtLeastEquivalent = "at_least_equivalent"
+	BedMatchModeUpgradeOnly       = "upgrade_only"
+)
+
+// list of upgrade dimensions of a upgrade_level penalty rule.
+const (
+	UpgradeDimensionView         = "view"
+	UpgradeDimensionBed          = "bed"
+	UpgradeDimensionRoomCategory = "room_category"
+)
+
+// list of compensation statuses.
+const (
+	CompensationStatusPending  = "pending"
+	CompensationStatusPaid     = "paid"
+	CompensationStatusWaived   = "waived"
+	CompensationStatusDisputed = "disputed"
+	CompensationStatusCanceled = "canceled"
+	CompensationStatusRefunded = "refunded"
+)
+
+// list of evaluation verdicts.
+const (
+	EvaluationVerdictSatisfied   = "satisfied"
+	EvaluationVerdictUnsatisfied = "unsatisfied"
+	EvaluationVerdictOverturned  = "overturned"
+)
+
+// list of evaluation types.
+const (
+	EvaluationTypeSLA          = "sla"
+	EvaluationTypeSatisfaction = "satisfaction"
+)
+
+// list of dispute statuses.
+const (
+	DisputeStatusOpen       = "open"
+	DisputeStatusUpheld     = "upheld"
+	DisputeStatusOverturned = "overturned"
+)
+
+// client identity attributes.
+const (
+	IdentityAttributeRole      = "role"
+	IdentityAttributeServiceID = "serviceId"
+)
+
+// list of client roles.
+const (
+	RoleProvider   = "provider"
+	RoleArbitrator = "arbitrator"
+	RoleAdmin      = "admin"
+)
+
+// DefaultPlatformMSPID is the MSP ID of the platform org, it seeds the configuration.
+const DefaultPlatformMSPID = "Org1MSP"
+
+// TransientKeyAccessKeyToken is the transient data key of a access key token, tokens are passed as transient
+// data so they are not recorded in the transaction.
+const TransientKeyAccessKeyToken = "accessKeyToken"
+
+// DefaultCompensationDueHours is the number of hours a provider has to fulfil
+// a compensation when the penalty rule does not specify it, it seeds the configuration.
+const DefaultCompensationDueHours = 72
+
 // agreement item code.
 const (
 	AgreementItemCodeViewSea      = "V001"
@@ -31,23 +150,34 @@ const (
 	AgreementItemCodeViewCity     = "V005"
 	AgreementItemCodeViewMountain = "V006"
 
-	AgreementItemCodeBedTwin  = "BE001"
-	AgreementItemCodeBedQueen = "BE002"
-	AgreementItemCodeBedKing  = "BE003"
+	AgreementItemCodeBedTwin    = "BE001"
+	AgreementItemCodeBedQueen   = "BE002"
+	AgreementItemCodeBedKing    = "BE003"
+	AgreementItemCodeBedDouble  = "BE004"
+	AgreementItemCodeBedSofaBed = "BE005"
+	AgreementItemCodeBedBunk    = "BE006"
+	AgreementItemCodeBedCrib    = "BE007"
 
 	AgreementItemCodeInteriorBathtub      = "IBA001"
 	AgreementItemCodeInteriorFlatScreenTV = "ITV001"
 
 	AgreementItemCodeServiceSauna          = "SSA001"
 	AgreementItemCodeServiceAirportShuttle = "SAS001"
+	AgreementItemCodeServiceBreakfast      = "SBR001"
+	AgreementItemCodeServiceHousekeeping   = "SHK001"
+	AgreementItemCodeServiceWifi           = "SWI001"
+	AgreementItemCodeServiceCheckIn        = "SCI001"
 
 	AgreementItemCodeOutdoorPatio   = "OPA001"
 	AgreementItemCodeOutdoorBalcony = "OBA001"
 
-	AgreementItemCodeRoomDesignSize = "RSI001"
+	AgreementItemCodeRoomDesignSize          = "RSI001"
+	AgreementItemCodeRoomDesignCeilingHeight = "RCH001"
+	AgreementItemCodeRoomDesignSuiteRooms    = "RSR001"
 )
 
-// SupportedAgreementCategories supported agreement categories.
+// SupportedAgreementCategories supported agreement categories built in the chaincode, the catalogue
+// maintained on the ledger may add or remove categories.
 var SupportedAgreementCategories = []string{
 	AgreementCategoryView,
 	AgreementCategoryService,
@@ -55,9 +185,10 @@ var SupportedAgreementCategories = []string{
 	AgreementCategoryRoomDesign,
 	AgreementCategoryOutdoor,
 	AgreementCategoryBed,
+	AgreementCategoryCustom,
 }
 
-// ViewLevelMapping maps a view to it's level.
+// ViewLevelMapping maps a view to it's level, it seeds the ranks of views in the catalogue.
 var ViewLevelMapping = map[string]int{
 	AgreementItemCodeViewMountain: 0,
 	AgreementItemCodeViewCity:     1,
@@ -67,11 +198,62 @@ var ViewLevelMapping = map[string]int{
 	AgreementItemCodeViewSea:      5,
 }
 
-// BedPointMapping maps a kind of bed to it's points.
+// BedPointMapping maps a kind of bed to it's points, it seeds the ranks of beds in the catalogue.
+// Twin, queen and king beds keep their 1:10:100 ratio so that verdicts of agreements on them do not change.
 var BedPointMapping = map[string]int{
-	AgreementItemCodeBedTwin:  1,
-	AgreementItemCodeBedQueen: 10,
-	AgreementItemCodeBedKing:  100,
+	AgreementItemCodeBedCrib:    2,
+	AgreementItemCodeBedSofaBed: 4,
+	AgreementItemCodeBedBunk:    6,
+	AgreementItemCodeBedTwin:    10,
+	AgreementItemCodeBedDouble:  40,
+	AgreementItemCodeBedQueen:   100,
+	AgreementItemCodeBedKing:    1000,
+}
+
+// units of room design measurements.
+const (
+	UnitSquareMeter = "m2"
+	UnitSquareFoot  = "ft2"
+	UnitMeter       = "m"
+	UnitFoot        = "ft"
+)
+
+// RoomDesignUnitFactors maps a room design code to factors converting it's units to the base unit.
+// Codes without units are counts.
+var RoomDesignUnitFactors = map[string]map[string]float64{
+	AgreementItemCodeRoomDesignSize: {
+		UnitSquareMeter: 1,
+		UnitSquareFoot:  0.09290304,
+	},
+	AgreementItemCodeRoomDesignCeilingHeight: {
+		UnitMeter: 1,
+		UnitFoot:  0.3048,
+	},
+	AgreementItemCodeRoomDesignSuiteRooms: {},
+}
+
+// RoomDesignBaseUnits maps a room design code to the unit used when none is given.
+var RoomDesignBaseUnits = map[string]string{
+	AgreementItemCodeRoomDesignSize:          UnitSquareMeter,
+	AgreementItemCodeRoomDesignCeilingHeight: UnitMeter,
+}
+
+// room categories.
+const (
+	RoomCategoryStandard    = "standard"
+	RoomCategorySuperior    = "superior"
+	RoomCategoryDeluxe      = "deluxe"
+	RoomCategoryJuniorSuite = "junior_suite"
+	RoomCategorySuite       = "suite"
+)
+
+// RoomCategoryLevelMapping maps a room category to it's level.
+var RoomCategoryLevelMapping = map[string]int{
+	RoomCategoryStandard:    0,
+	RoomCategorySuperior:    1,
+	RoomCategoryDeluxe:      2,
+	RoomCategoryJuniorSuite: 3,
+	RoomCategorySuite:       4,
 }
 
 // airport shuttle statuses.
@@ -85,8 +267,32 @@ const (
 	AirportShuttleStatusCanceled            = "canceled"
 )
 
+// parties at fault of an airport shuttle evaluation.
+const (
+	ShuttleFaultDriver = "driver"
+	ShuttleFaultGuest  = "guest"
+)
+
 // sauna request statuses.
 const (
 	SaunaRequestStatusSuccess = "success"
 	SaunaRequestStatusFail    = "fail"
 )
+
+// list of batch modes, a batch is all or nothing by default.
+const (
+	BatchModeAllOrNothing = "all_or_nothing"
+	BatchModeBestEffort   = "best_effort"
+)
+
+// ClockLayout is the layout of clock times of agreement items.
+const ClockLayout = "15:04"
+
+// DateLayout is the layout of dates of a stay.
+const DateLayout = "2006-01-02"
+
+// sauna verification modes.
+const (
+	SaunaModeFailures     = "failures"
+	SaunaModeAvailability = "availability"
+)
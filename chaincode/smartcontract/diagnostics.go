@@ -0,0 +1,100 @@
+//go:build debug
+// +build debug
+
+package smartcontract
+
+import (
+	"bytes"
+	b64 "encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// DiagnosticsContract provides functions for debugging the chaincode, it is only registered
+// when the chaincode is built with the debug build tag.
+type DiagnosticsContract struct {
+	contractapi.Contract
+}
+
+// PostPenaltyRule posts penalty rule.
+func (d *DiagnosticsContract) PostPenaltyRule(ctx contractapi.TransactionContextInterface) (string, error) {
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	enforcePenaltyRulesRequest := &EnforcePenaltyRulesRequest{
+		ReservationID: "rid",
+		AgreementID:   "aid",
+	}
+
+	err = EnforcePenaltyRules(config, enforcePenaltyRulesRequest, "token")
+	if err != nil {
+		return "", fmt.Errorf("fail to enforce penalty rule %v", err)
+	}
+
+	return "success", nil
+}
+
+// PostUsers posts users.
+func (d *DiagnosticsContract) PostUsers(_ contractapi.TransactionContextInterface) error {
+	reqBody, err := json.Marshal(map[string]interface{}{
+		"title":  "foo",
+		"body":   "bar",
+		"userId": 1,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	url := "https://jsonplaceholder.typicode.com/posts"
+	resp, err := http.Post(url, "application/json", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf(fmt.Sprintf("PostUsers with response :%d", resp.StatusCode))
+	}
+
+	_, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// TestTime tests time.
+func (d *DiagnosticsContract) TestTime(_ contractapi.TransactionContextInterface, data string) (*SaunaRequest, error) {
+	dEvaData, err := b64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("can not decode evaluation data from base64: %v", err)
+	}
+	var evaData []*EvaluationData
+	err = json.Unmarshal(dEvaData, &evaData)
+	if err != nil {
+		return nil, fmt.Errorf("can not unmarshal evaluation data: %v", err)
+	}
+
+	requests := evaData[0].SaunaRequests
+
+	// sort requests by request time
+	sort.SliceStable(requests, func(i, j int) bool {
+		return requests[i].RequestAt.After(requests[j].RequestAt)
+	})
+
+	if requests[0].RequestAt.Before(requests[1].RequestAt) {
+		return requests[0], nil
+	}
+
+	return requests[1], nil
+}
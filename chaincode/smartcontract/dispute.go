@@ -0,0 +1,382 @@
+package smartcontract
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Dispute keys
+const (
+	disputeObjectType = "dispute"
+)
+
+// Dispute events
+const (
+	EventDisputeOpened   = "DisputeOpened"
+	EventDisputeResolved = "DisputeResolved"
+)
+
+// OpenDispute opens a dispute on an unsatisfied evaluation at the time of the transaction.
+// Only the provider of the evaluated service can open it. A pending compensation of the
+// evaluation is frozen until the dispute is resolved.
+func (s *EvaluationsContract) OpenDispute(ctx contractapi.TransactionContextInterface, eid, evidenceHash, reason string) (*Dispute, error) {
+	if evidenceHash == "" {
+		return nil, fmt.Errorf("the evidence hash must not be empty")
+	}
+
+	evaluation, err := s.ReadEvaluation(ctx, eid)
+	if err != nil {
+		return nil, err
+	}
+
+	err = assertServiceOwner(ctx, evaluation.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if evaluation.Verdict != EvaluationVerdictUnsatisfied {
+		return nil, fmt.Errorf("only unsatisfied evaluations can be disputed, the evaluation %s is %s", eid, evaluation.Verdict)
+	}
+
+	exist, err := s.DisputeExists(ctx, eid)
+	if err != nil {
+		return nil, err
+	}
+	if exist {
+		return nil, fmt.Errorf("the evaluation %s has already been disputed", eid)
+	}
+
+	openedBy, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, err
+	}
+	openedAt, err := txTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	compensation, err := s.findCompensationWithStatus(ctx, eid, CompensationStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	if compensation != nil {
+		compensation.Status = CompensationStatusDisputed
+		compensation.TxID = ctx.GetStub().GetTxID()
+		err = s.putCompensation(ctx, compensation)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	dispute := &Dispute{
+		DocType:      "Dispute",
+		DisputeID:    eid,
+		EvaluationID: eid,
+		ServiceID:    evaluation.ServiceID,
+		AgreementID:  evaluation.AgreementID,
+		Status:       DisputeStatusOpen,
+		Reason:       reason,
+		EvidenceHash: evidenceHash,
+		OpenedBy:     openedBy,
+		OpenedAt:     openedAt.Format(RFC3339),
+		TxID:         ctx.GetStub().GetTxID(),
+	}
+
+	err = s.putDispute(ctx, dispute, EventDisputeOpened)
+	if err != nil {
+		return nil, err
+	}
+
+	return dispute, nil
+}
+
+// ResolveDispute resolves an open dispute as upheld or overturned at the time of the transaction.
+// Only an arbitrator of the platform org can resolve it. An upheld dispute returns it's compensation to
+// pending with the due time extended by the time the dispute was open. An overturned dispute reverses
+// the effect of the evaluation on the satisfaction rate and on the rule violations: a paid compensation
+// is refunded and any other compensation is canceled.
+func (s *EvaluationsContract) ResolveDispute(ctx contractapi.TransactionContextInterface, did, outcome, resolution string) (*Dispute, error) {
+	if outcome != DisputeStatusUpheld && outcome != DisputeStatusOverturned {
+		return nil, fmt.Errorf("the dispute can not be resolved with outcome %s", outcome)
+	}
+
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = assertPlatformRole(ctx, config, RoleArbitrator)
+	if err != nil {
+		return nil, err
+	}
+
+	dispute, err := s.ReadDispute(ctx, did)
+	if err != nil {
+		return nil, err
+	}
+	if dispute.Status != DisputeStatusOpen {
+		return nil, fmt.Errorf("the dispute %s has already been resolved", did)
+	}
+
+	resolvedBy, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, err
+	}
+	resolvedAt, err := txTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// the compensation is disputed unless it was settled before the dispute was opened
+	compensation, err := s.findCompensation(ctx, dispute.EvaluationID)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := false
+	switch outcome {
+	case DisputeStatusUpheld:
+		if compensation != nil && compensation.Status == CompensationStatusDisputed {
+			compensation.Status = CompensationStatusPending
+			err = extendDueTime(compensation, dispute, resolvedAt)
+			if err != nil {
+				return nil, err
+			}
+			changed = true
+		}
+	case DisputeStatusOverturned:
+		service, err := readService(ctx, dispute.ServiceID)
+		if err != nil {
+			return nil, err
+		}
+		agreement, err := findAgreement(service, dispute.AgreementID)
+		if err != nil {
+			return nil, err
+		}
+
+		evaluation, err := s.ReadEvaluation(ctx, dispute.EvaluationID)
+		if err != nil {
+			return nil, err
+		}
+
+		if agreement.TotalUnsatisfied > 0 {
+			agreement.TotalUnsatisfied--
+		}
+		// the overturned evaluation scores as a satisfied one
+		if evaluation.Scored && agreement.ScoredFeedbacks > 0 {
+			agreement.TotalScore += float64(1 - evaluation.Score)
+			agreement.AverageScore = float32(agreement.TotalScore / float64(agreement.ScoredFeedbacks))
+		}
+		for _, e := range agreement.RecentEvaluations {
+			if e.EvaluationID == evaluation.EvaluationID {
+				e.Satisfied = true
+				e.Score = 1
+			}
+		}
+		refreshSatisfactionRate(config, service, agreement)
+
+		if compensation != nil {
+			// canceled and refunded compensations are no longer counted as rule violations
+			switch compensation.Status {
+			case CompensationStatusPaid:
+				compensation.Status = CompensationStatusRefunded
+				compensation.RefundedAt = resolvedAt.Format(RFC3339)
+				changed = true
+			case CompensationStatusPending, CompensationStatusDisputed, CompensationStatusWaived:
+				compensation.Status = CompensationStatusCanceled
+				changed = true
+			}
+			if changed {
+				err = s.refreshRuleAbidingCounts(ctx, service, agreement, compensation)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		jService, err := json.Marshal(service)
+		if err != nil {
+			return nil, err
+		}
+		err = ctx.GetStub().PutState(service.ServiceID, jService)
+		if err != nil {
+			return nil, fmt.Errorf("fail to update rates for service %s", service.ServiceID)
+		}
+
+		evaluation.Verdict = EvaluationVerdictOverturned
+		jEvaluation, err := json.Marshal(evaluation)
+		if err != nil {
+			return nil, err
+		}
+		err = ctx.GetStub().PutState(evaluation.EvaluationID, jEvaluation)
+		if err != nil {
+			return nil, fmt.Errorf("fail to update evaluation %s", evaluation.EvaluationID)
+		}
+	}
+
+	if changed {
+		compensation.TxID = ctx.GetStub().GetTxID()
+		err = s.putCompensation(ctx, compensation)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	dispute.Status = outcome
+	dispute.Resolution = resolution
+	dispute.ResolvedBy = resolvedBy
+	dispute.ResolvedAt = resolvedAt.Format(RFC3339)
+	dispute.TxID = ctx.GetStub().GetTxID()
+
+	err = s.putDispute(ctx, dispute, EventDisputeResolved)
+	if err != nil {
+		return nil, err
+	}
+
+	return dispute, nil
+}
+
+// DisputeExists returns true when dispute with given id exists in world state.
+func (s *EvaluationsContract) DisputeExists(ctx contractapi.TransactionContextInterface, did string) (bool, error) {
+	disputeKey, err := ctx.GetStub().CreateCompositeKey(disputeObjectType, []string{did})
+	if err != nil {
+		return false, err
+	}
+
+	exist, err := ctx.GetStub().GetState(disputeKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to read dispute from world state: %v", err)
+	}
+
+	return exist != nil, nil
+}
+
+// ReadDispute returns the dispute stored in the world state with given id.
+func (s *EvaluationsContract) ReadDispute(ctx contractapi.TransactionContextInterface, did string) (*Dispute, error) {
+	disputeKey, err := ctx.GetStub().CreateCompositeKey(disputeObjectType, []string{did})
+	if err != nil {
+		return nil, err
+	}
+
+	jDispute, err := ctx.GetStub().GetState(disputeKey)
+	if err != nil {
+		return nil, err
+	}
+	if jDispute == nil {
+		return nil, fmt.Errorf("the dispute %s does not exist", did)
+	}
+
+	var dispute Dispute
+	err = json.Unmarshal(jDispute, &dispute)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dispute, nil
+}
+
+// GetDisputeHistory returns all versions of a dispute recorded in the ledger.
+func (s *EvaluationsContract) GetDisputeHistory(ctx contractapi.TransactionContextInterface, did string) ([]*DisputeHistory, error) {
+	disputeKey, err := ctx.GetStub().CreateCompositeKey(disputeObjectType, []string{did})
+	if err != nil {
+		return nil, err
+	}
+
+	historyIterator, err := ctx.GetStub().GetHistoryForKey(disputeKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dispute history: %v", err)
+	}
+
+	defer historyIterator.Close()
+
+	var histories []*DisputeHistory
+	for historyIterator.HasNext() {
+		modification, err := historyIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		history := &DisputeHistory{
+			TxID:     modification.TxId,
+			IsDelete: modification.IsDelete,
+		}
+		if ts := modification.Timestamp; ts != nil {
+			history.Timestamp = time.Unix(ts.Seconds, int64(ts.Nanos)).UTC().Format(RFC3339)
+		}
+		if !modification.IsDelete {
+			var dispute Dispute
+			err = json.Unmarshal(modification.Value, &dispute)
+			if err != nil {
+				return nil, err
+			}
+			history.Dispute = &dispute
+		}
+		histories = append(histories, history)
+	}
+
+	return histories, nil
+}
+
+// putDispute writes the dispute to the world state and emits the given event.
+func (s *EvaluationsContract) putDispute(ctx contractapi.TransactionContextInterface, dispute *Dispute, event string) error {
+	disputeKey, err := ctx.GetStub().CreateCompositeKey(disputeObjectType, []string{dispute.DisputeID})
+	if err != nil {
+		return err
+	}
+
+	jDispute, err := json.Marshal(dispute)
+	if err != nil {
+		return err
+	}
+
+	err = ctx.GetStub().PutState(disputeKey, jDispute)
+	if err != nil {
+		return fmt.Errorf("fail to save dispute %s", dispute.DisputeID)
+	}
+
+	return ctx.GetStub().SetEvent(event, jDispute)
+}
+
+// extendDueTime extends the due time of the compensation of an upheld dispute by the time the
+// dispute was open, the provider does not have to fulfil it while it is disputed.
+func extendDueTime(compensation *Compensation, dispute *Dispute, resolvedAt time.Time) error {
+	openedAt, err := ParseTime(dispute.OpenedAt)
+	if err != nil {
+		return fmt.Errorf("can not parse dispute time %s: %v", dispute.OpenedAt, err)
+	}
+	dueAt, err := ParseTime(compensation.DueAt)
+	if err != nil {
+		return err
+	}
+
+	if resolvedAt.After(openedAt) {
+		compensation.DueAt = dueAt.Add(resolvedAt.Sub(openedAt)).Format(RFC3339)
+	}
+
+	return nil
+}
+
+// findCompensation returns the compensation of an evaluation, or nil when the evaluation has no compensation.
+func (s *EvaluationsContract) findCompensation(ctx contractapi.TransactionContextInterface, eid string) (*Compensation, error) {
+	exist, err := s.CompensationExists(ctx, eid)
+	if err != nil || !exist {
+		return nil, err
+	}
+
+	return s.ReadCompensation(ctx, eid)
+}
+
+// findCompensationWithStatus returns the compensation of an evaluation when it has the given status.
+func (s *EvaluationsContract) findCompensationWithStatus(ctx contractapi.TransactionContextInterface, eid, status string) (*Compensation, error) {
+	compensation, err := s.findCompensation(ctx, eid)
+	if err != nil || compensation == nil {
+		return nil, err
+	}
+	if compensation.Status != status {
+		return nil, nil
+	}
+
+	return compensation, nil
+}
@@ -0,0 +1,227 @@
+package smartcontract
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolveDispute(t *testing.T) {
+	s := &EvaluationsContract{}
+	evaluatedAt := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	openedAt := evaluatedAt.Add(time.Hour)
+	resolvedAt := openedAt.Add(100 * time.Hour)
+
+	tests := []struct {
+		name    string
+		outcome string
+
+		compensationStatus string
+		dueAt              time.Time
+		verdict            string
+		totalUnsatisfied   uint
+		satisfactionRate   float32
+	}{
+		{
+			name:               "upheld",
+			outcome:            DisputeStatusUpheld,
+			compensationStatus: CompensationStatusPending,
+			dueAt:              evaluatedAt.Add(DefaultCompensationDueHours*time.Hour + 100*time.Hour),
+			verdict:            EvaluationVerdictUnsatisfied,
+			totalUnsatisfied:   1,
+			satisfactionRate:   0,
+		},
+		{
+			name:               "overturned",
+			outcome:            DisputeStatusOverturned,
+			compensationStatus: CompensationStatusCanceled,
+			dueAt:              evaluatedAt.Add(DefaultCompensationDueHours * time.Hour),
+			verdict:            EvaluationVerdictOverturned,
+			satisfactionRate:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, stub := newTestContext(t)
+			putTestService(t, stub, shuttleService(recorderServiceID))
+			_, err := s.HandleSatisfactionEvaluationEvent(ctx, recorderServiceID, recorderAgreementID, "e1", "r1", "hash", evaluatedAt.Format(RFC3339), false, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			setRole(ctx, RoleProvider, "service2")
+			_, err = s.OpenDispute(ctx, "e1", "evidence", "bad data")
+			if err == nil {
+				t.Fatal("the provider of another service opened a dispute")
+			}
+
+			setTxTime(stub, openedAt)
+			setRole(ctx, RoleProvider, recorderServiceID)
+			dispute, err := s.OpenDispute(ctx, "e1", "evidence", "bad data")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if dispute.OpenedAt != openedAt.Format(RFC3339) {
+				t.Errorf("opened at = %s, want %s", dispute.OpenedAt, openedAt.Format(RFC3339))
+			}
+
+			compensation, err := s.ReadCompensation(ctx, "e1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if compensation.Status != CompensationStatusDisputed {
+				t.Errorf("compensation status = %s, want %s", compensation.Status, CompensationStatusDisputed)
+			}
+
+			_, err = s.ResolveDispute(ctx, "e1", tt.outcome, "resolution")
+			if err == nil {
+				t.Fatal("a provider resolved the dispute")
+			}
+			ctx.SetClientIdentity(&testIdentity{id: RoleArbitrator, mspID: "Org2MSP", attrs: map[string]string{IdentityAttributeRole: RoleArbitrator}})
+			_, err = s.ResolveDispute(ctx, "e1", tt.outcome, "resolution")
+			if err == nil {
+				t.Fatal("an arbitrator of another org resolved the dispute")
+			}
+
+			setTxTime(stub, resolvedAt)
+			setRole(ctx, RoleArbitrator, "")
+			dispute, err = s.ResolveDispute(ctx, "e1", tt.outcome, "resolution")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if dispute.Status != tt.outcome || dispute.ResolvedAt != resolvedAt.Format(RFC3339) {
+				t.Errorf("dispute = %s at %s, want %s at %s", dispute.Status, dispute.ResolvedAt, tt.outcome, resolvedAt.Format(RFC3339))
+			}
+
+			compensation, err = s.ReadCompensation(ctx, "e1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if compensation.Status != tt.compensationStatus || compensation.DueAt != tt.dueAt.Format(RFC3339) {
+				t.Errorf("compensation = %s due at %s, want %s due at %s", compensation.Status, compensation.DueAt, tt.compensationStatus, tt.dueAt.Format(RFC3339))
+			}
+
+			evaluation, err := s.ReadEvaluation(ctx, "e1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if evaluation.Verdict != tt.verdict {
+				t.Errorf("verdict = %s, want %s", evaluation.Verdict, tt.verdict)
+			}
+
+			service, err := readService(ctx, recorderServiceID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if service.Agreements[0].TotalUnsatisfied != tt.totalUnsatisfied || service.SatisfactionRate != tt.satisfactionRate {
+				t.Errorf("unsatisfied = %d, rate = %v, want %d, %v", service.Agreements[0].TotalUnsatisfied, service.SatisfactionRate, tt.totalUnsatisfied, tt.satisfactionRate)
+			}
+
+			// the extended compensation of an upheld dispute is not overdue when the dispute is resolved
+			setRole(ctx, RoleAdmin, "")
+			_, err = s.ProcessOverdueCompensations(ctx, recorderServiceID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			compensation, err = s.ReadCompensation(ctx, "e1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if compensation.Overdue {
+				t.Error("the compensation is overdue right after the dispute is resolved")
+			}
+
+			_, err = s.ResolveDispute(ctx, "e1", tt.outcome, "resolution")
+			if err == nil {
+				t.Error("a resolved dispute is resolved again")
+			}
+		})
+	}
+}
+
+func TestResolveDisputeOfSettledCompensation(t *testing.T) {
+	s := &EvaluationsContract{}
+	evaluatedAt := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	resolvedAt := evaluatedAt.Add(2 * time.Hour)
+
+	tests := []struct {
+		name       string
+		settledBy  string
+		status     string
+		outcome    string
+		wantStatus string
+		refunded   bool
+		violations uint
+	}{
+		{name: "paid and overturned", settledBy: RoleProvider, status: CompensationStatusPaid, outcome: DisputeStatusOverturned, wantStatus: CompensationStatusRefunded, refunded: true},
+		{name: "waived and overturned", settledBy: RoleArbitrator, status: CompensationStatusWaived, outcome: DisputeStatusOverturned, wantStatus: CompensationStatusCanceled},
+		{name: "paid and upheld", settledBy: RoleProvider, status: CompensationStatusPaid, outcome: DisputeStatusUpheld, wantStatus: CompensationStatusPaid, violations: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, stub := newTestContext(t)
+			putTestService(t, stub, shuttleService(recorderServiceID))
+			_, err := s.HandleSatisfactionEvaluationEvent(ctx, recorderServiceID, recorderAgreementID, "e1", "r1", "hash", evaluatedAt.Format(RFC3339), false, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			setTxTime(stub, evaluatedAt.Add(time.Hour))
+			setRole(ctx, tt.settledBy, recorderServiceID)
+			_, err = s.SettleCompensation(ctx, "e1", tt.status, "evidence")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			setRole(ctx, RoleProvider, recorderServiceID)
+			_, err = s.OpenDispute(ctx, "e1", "evidence", "bad data")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			setTxTime(stub, resolvedAt)
+			setRole(ctx, RoleArbitrator, "")
+			_, err = s.ResolveDispute(ctx, "e1", tt.outcome, "resolution")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			compensation, err := s.ReadCompensation(ctx, "e1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if compensation.Status != tt.wantStatus {
+				t.Errorf("compensation status = %s, want %s", compensation.Status, tt.wantStatus)
+			}
+			if (compensation.RefundedAt == resolvedAt.Format(RFC3339)) != tt.refunded {
+				t.Errorf("refunded at = %q, want refunded %v", compensation.RefundedAt, tt.refunded)
+			}
+
+			service, err := readService(ctx, recorderServiceID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if service.Agreements[0].TotalRuleViolations != tt.violations {
+				t.Errorf("rule violations = %d, want %d", service.Agreements[0].TotalRuleViolations, tt.violations)
+			}
+		})
+	}
+}
+
+func TestOpenDisputeOnSatisfiedEvaluation(t *testing.T) {
+	s := &EvaluationsContract{}
+	ctx, stub := newTestContext(t)
+	putTestService(t, stub, shuttleService(recorderServiceID))
+
+	_, err := s.HandleSatisfactionEvaluationEvent(ctx, recorderServiceID, recorderAgreementID, "e1", "r1", "hash", recorderAt, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setRole(ctx, RoleProvider, recorderServiceID)
+	_, err = s.OpenDispute(ctx, "e1", "evidence", "bad data")
+	if err == nil {
+		t.Fatal("a satisfied evaluation is disputed")
+	}
+}
@@ -0,0 +1,788 @@
+package smartcontract
+
+import (
+	b64 "encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// EvaluateSLA handles evaluating SLA request.
+func (s *EvaluationsContract) EvaluateSLA(ctx contractapi.TransactionContextInterface, sid, aid, eid, eData, hash, at string) (*EvaluationResult, error) {
+	recorder, err := newEvaluationRecorder(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	service, agreement, err := recorder.agreement(ctx, sid, aid)
+	if err != nil {
+		return nil, err
+	}
+
+	eResult, err := s.verifyEvaluationData(ctx, service, agreement, eData)
+	if err != nil {
+		return nil, err
+	}
+
+	err = recorder.record(ctx, service, agreement, &evaluationRecord{
+		evaluationType: EvaluationTypeSLA,
+		eid:            eid,
+		hash:           hash,
+		at:             at,
+		result:         eResult,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = recorder.save(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return eResult, nil
+}
+
+// SimulateSLA verifies evaluation data against a agreement of a service without recording anything,
+// so that the outcome of an evaluation can be previewed.
+func (s *EvaluationsContract) SimulateSLA(ctx contractapi.TransactionContextInterface, sid, aid, eData string) (*EvaluationResult, error) {
+	service, err := readService(ctx, sid)
+	if err != nil {
+		return nil, err
+	}
+
+	agreement, err := findAgreement(service, aid)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.verifyEvaluationData(ctx, service, agreement, eData)
+}
+
+// verifyEvaluationData decodes the base64 evaluation data and verifies it against the agreement of the service.
+func (s *EvaluationsContract) verifyEvaluationData(ctx contractapi.TransactionContextInterface, service *Service, agreement *Agreement, eData string) (*EvaluationResult, error) {
+	dEvaData, err := b64.StdEncoding.DecodeString(eData)
+	if err != nil {
+		return nil, fmt.Errorf("can not decode evaluation data from base64: %v", err)
+	}
+	payloads, err := decodeEvaluationData(agreement.Category, dEvaData)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.verifyAgreement(ctx, agreement, payloads, service.ViewRanking)
+}
+
+// UpdateRuleAbidingRate handles updating SLA rule-abiding rate request. The violation is the compensation
+// of the evaluation with given id, it is settled as paid when it has been compensated and waived otherwise,
+// at the time of the transaction. The reported time is kept for clients and is not used.
+func (s *EvaluationsContract) UpdateRuleAbidingRate(ctx contractapi.TransactionContextInterface, sid, aid, eid, hash, at string, compensated bool) (*Service, error) {
+	return s.recordRuleAbidingEvaluation(ctx, sid, aid, eid, hash, compensated)
+}
+
+// recordRuleAbidingEvaluation settles the compensation of the evaluation through the recorder.
+func (s *EvaluationsContract) recordRuleAbidingEvaluation(ctx contractapi.TransactionContextInterface, sid, aid, eid, hash string, compensated bool) (*Service, error) {
+	compensation, err := s.ReadCompensation(ctx, eid)
+	if err != nil {
+		return nil, err
+	}
+	if compensation.ServiceID != sid || compensation.AgreementID != aid {
+		return nil, fmt.Errorf("the compensation %s is not of the agreement %s of service %s", eid, aid, sid)
+	}
+
+	status := CompensationStatusWaived
+	if compensated {
+		status = CompensationStatusPaid
+	}
+
+	recorder, err := newEvaluationRecorder(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	err = recorder.settle(ctx, compensation, status, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	err = recorder.save(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return recorder.services[sid], nil
+}
+
+// VerifySLA verifies SLA agreement, views are ranked by the catalogue.
+func (s *EvaluationsContract) VerifySLA(ctx contractapi.TransactionContextInterface, a *Agreement, eData []*EvaluationData) (*EvaluationResult, error) {
+	return s.verifyAgreement(ctx, a, payloadsOf(a.Category, eData), nil)
+}
+
+// verifyAgreement verifies the agreement and selects the triggered penalty rules. Views are ranked by
+// the given view ranking of the service, or by the catalogue when it is empty.
+func (s *EvaluationsContract) verifyAgreement(ctx contractapi.TransactionContextInterface, a *Agreement, payloads []evaluationPayload, viewRanking []string) (*EvaluationResult, error) {
+	c, err := loadCatalogue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	eResult, err := s.verifySLA(ctx, c, config, a, payloads, viewRanking)
+	if err != nil {
+		return nil, err
+	}
+
+	eResult.RepeatOffenses = a.TotalUnsatisfied
+	err = applyPenaltyRules(c, a, viewRanking, eResult)
+	if err != nil {
+		return nil, err
+	}
+
+	return eResult, nil
+}
+
+func (s *EvaluationsContract) verifySLA(ctx contractapi.TransactionContextInterface, c *catalogue, config *ContractConfig, a *Agreement, payloads []evaluationPayload, viewRanking []string) (*EvaluationResult, error) {
+	if !c.hasCategory(a.Category) {
+		return nil, fmt.Errorf("agreement category %s has not supported yet", a.Category)
+	}
+
+	if len(payloads) == 0 {
+		return &EvaluationResult{
+			Satisfied:     false,
+			PenaltyRules:  defaultPenaltyRules(a, 0),
+			FailureReason: "no data to evaluate",
+		}, nil
+	}
+
+	// beds are verified together as a configuration of the room
+	if a.Category == AgreementCategoryBed {
+		eResult, err := verifyBedAgreement(ctx, a, facilityPayloads(payloads))
+		if err != nil {
+			return nil, err
+		}
+
+		itemResults := make([]*EvaluationResult, len(a.Items))
+		for i := range a.Items {
+			itemResults[i] = eResult
+		}
+		return combineItemResults(config, a, itemResults), nil
+	}
+
+	var itemResults []*EvaluationResult
+	for _, agreementItem := range a.Items {
+		itemResult, err := s.verifyAgreementItem(ctx, itemAgreement(a, agreementItem), payloads, viewRanking)
+		if err != nil {
+			return nil, err
+		}
+		itemResults = append(itemResults, itemResult)
+	}
+
+	return combineItemResults(config, a, itemResults), nil
+}
+
+// verifyAgreementItem verifies a agreement which has the only item to verify.
+func (s *EvaluationsContract) verifyAgreementItem(ctx contractapi.TransactionContextInterface, a *Agreement, payloads []evaluationPayload, viewRanking []string) (*EvaluationResult, error) {
+	agreementItem := a.Items[0]
+
+	switch a.Category {
+	case AgreementCategoryService, AgreementCategoryRoomDesign:
+		payload := findPayload(payloads, agreementItem.Code)
+		if payload == nil {
+			return &EvaluationResult{
+				Satisfied:     false,
+				PenaltyRules:  defaultPenaltyRules(a, 0),
+				FailureReason: fmt.Sprintf("no data to evaluate item %s", agreementItem.Code),
+			}, nil
+		}
+
+		return verifyItemPayload(a, payload)
+	case AgreementCategoryCustom:
+		return verifyCustomAgreement(a, customPayloads(payloads))
+	case AgreementCategoryView:
+		return verifyViewAgreement(ctx, a, viewPayloads(payloads), viewRanking)
+	default:
+		satisfied := false
+		var score float32
+		for _, item := range facilityPayloads(payloads) {
+			if item.Code != agreementItem.Code {
+				continue
+			}
+			if agreementItem.Quantity == 0 || item.Quantity >= agreementItem.Quantity {
+				satisfied = true
+				break
+			}
+			// fewer facilities than agreed partially satisfy the agreement
+			score = ratioScore(float64(item.Quantity), float64(agreementItem.Quantity))
+		}
+
+		return &EvaluationResult{
+			Satisfied:    satisfied,
+			PenaltyRules: defaultPenaltyRules(a, 0),
+			Score:        score,
+		}, nil
+	}
+}
+
+// itemVerifierCodes lists item codes of the service and room design categories which have verifiers.
+// Items of the other categories are verified by their category.
+var itemVerifierCodes = []string{
+	AgreementItemCodeServiceAirportShuttle,
+	AgreementItemCodeServiceSauna,
+	AgreementItemCodeServiceBreakfast,
+	AgreementItemCodeServiceHousekeeping,
+	AgreementItemCodeServiceWifi,
+	AgreementItemCodeServiceCheckIn,
+	AgreementItemCodeRoomDesignSize,
+	AgreementItemCodeRoomDesignCeilingHeight,
+	AgreementItemCodeRoomDesignSuiteRooms,
+}
+
+// hasItemVerifier returns true when the chaincode can verify items of the code in the category.
+func hasItemVerifier(category, code string) bool {
+	if category != AgreementCategoryService && category != AgreementCategoryRoomDesign {
+		return true
+	}
+
+	return StringInSlice(code, itemVerifierCodes)
+}
+
+// verifyItemPayload verifies a agreement which has the only item against the payload of the item
+// with the verifier of the payload type.
+func verifyItemPayload(a *Agreement, payload evaluationPayload) (*EvaluationResult, error) {
+	code := payload.itemCode()
+	if !StringInSlice(code, itemVerifierCodes) {
+		return nil, MakeErrorAgreementItemCodeDoesNotSupport(code, a.Category)
+	}
+
+	switch p := payload.(type) {
+	case *airportShuttlePayload:
+		return verifyAirportShuttleAgreement(a, p)
+	case *saunaPayload:
+		return verifySaunaAgreement(a, p)
+	case *breakfastPayload:
+		return verifyBreakfastAgreement(a, p)
+	case *housekeepingPayload:
+		return verifyHousekeepingAgreement(a, p)
+	case *wifiPayload:
+		return verifyWifiAgreement(a, p)
+	case *checkInPayload:
+		return verifyCheckInAgreement(a, p)
+	case *roomDesignPayload:
+		return verifyRoomDesignMeasurement(a, p)
+	default:
+		return nil, MakeErrorAgreementItemCodeDoesNotSupport(code, a.Category)
+	}
+}
+
+// itemAgreement returns a copy of the agreement which has only the given item.
+func itemAgreement(a *Agreement, agreementItem *AgreementItem) *Agreement {
+	ia := *a
+	ia.Items = []*AgreementItem{agreementItem}
+	return &ia
+}
+
+// combineItemResults combines results of agreement items into the result of the agreement
+// under the item policy of the agreement. Penalty facts are taken from the most severe failed item.
+func combineItemResults(config *ContractConfig, a *Agreement, itemResults []*EvaluationResult) *EvaluationResult {
+	eResult := &EvaluationResult{}
+
+	var passed, total, passedWeight, totalWeight, weightedScore float64
+	var reasons []string
+	var worst *EvaluationResult
+	for i, itemResult := range itemResults {
+		weight := a.Items[i].Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		total++
+		totalWeight += weight
+
+		score := resultScore(itemResult)
+		weightedScore += float64(score) * weight
+		if !itemResult.Satisfied && itemResult.Severity == "" {
+			itemResult.Severity = severityFromScore(score)
+		}
+
+		eResult.Items = append(eResult.Items, &ItemEvaluationResult{
+			Code:          a.Items[i].Code,
+			Satisfied:     itemResult.Satisfied,
+			FailureReason: itemResult.FailureReason,
+			Score:         score,
+			Severity:      itemResult.Severity,
+			Fault:         itemResult.Fault,
+		})
+
+		if itemResult.Satisfied {
+			passed++
+			passedWeight += weight
+			continue
+		}
+
+		if itemResult.FailureReason != "" && !StringInSlice(itemResult.FailureReason, reasons) {
+			reasons = append(reasons, itemResult.FailureReason)
+		}
+		if worst == nil || severityRank(itemResult.Severity) > severityRank(worst.Severity) {
+			worst = itemResult
+		}
+	}
+
+	policy := a.ItemPolicy
+	if policy == "" {
+		policy = config.ItemPolicy
+	}
+
+	switch policy {
+	case ItemPolicyMajority:
+		eResult.Satisfied = passed*2 > total
+	case ItemPolicyWeighted:
+		threshold := float64(a.ItemPassThreshold)
+		if threshold <= 0 {
+			threshold = float64(config.ItemPassThreshold)
+		}
+		eResult.Satisfied = totalWeight == 0 || passedWeight/totalWeight >= threshold
+	default:
+		eResult.Satisfied = passed == total
+	}
+
+	eResult.Score = 1
+	if totalWeight > 0 {
+		eResult.Score = float32(weightedScore / totalWeight)
+	}
+
+	if !eResult.Satisfied && worst != nil {
+		eResult.PenaltyRules = worst.PenaltyRules
+		eResult.Severity = worst.Severity
+		eResult.DelayMinutes = worst.DelayMinutes
+		eResult.Failures = worst.Failures
+		eResult.Fault = worst.Fault
+		eResult.FailureReason = strings.Join(reasons, "; ")
+	}
+
+	return eResult
+}
+
+// resultScore returns the score of a evaluation result, a satisfied result without score scores 1.
+func resultScore(eResult *EvaluationResult) float32 {
+	if eResult.Satisfied && eResult.Score == 0 {
+		return 1
+	}
+	return eResult.Score
+}
+
+// ratioScore scores how much of the agreed value is delivered.
+func ratioScore(actual, agreed float64) float32 {
+	if agreed <= 0 || actual >= agreed {
+		return 1
+	}
+	if actual <= 0 {
+		return 0
+	}
+	return float32(actual / agreed)
+}
+
+// delayScore scores a delay: 1 within the short wait time, down to 0.5 at the long wait time
+// and down to 0 at twice the long wait time.
+func delayScore(delay float64, shortWaitTime, longWaitTime int) float32 {
+	short := float64(shortWaitTime)
+	long := float64(longWaitTime)
+	switch {
+	case delay <= short:
+		return 1
+	case delay <= long:
+		return float32(1 - 0.5*(delay-short)/(long-short))
+	case delay >= 2*long:
+		return 0
+	default:
+		return float32(0.5 * (2*long - delay) / long)
+	}
+}
+
+// severityFromScore derives the severity of an unsatisfied result from it's score.
+func severityFromScore(score float32) string {
+	switch {
+	case score >= 0.5:
+		return PenaltySeverityMinor
+	case score > 0:
+		return PenaltySeverityMajor
+	default:
+		return PenaltySeverityCritical
+	}
+}
+
+// severityRank ranks penalty severities, an evaluation without severity is a major one.
+func severityRank(severity string) int {
+	switch severity {
+	case PenaltySeverityMinor:
+		return 0
+	case PenaltySeverityCritical:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// VerifyRoomSizeAgreement verify room size agreement.
+func (s *EvaluationsContract) VerifyRoomSizeAgreement(_ contractapi.TransactionContextInterface, a *Agreement, data *EvaluationData) (*EvaluationResult, error) {
+	if data.Code != AgreementItemCodeRoomDesignSize {
+		return nil, fmt.Errorf("evaluation data code %s is not room size code", data.Code)
+	}
+
+	return verifyRoomDesignData(a, data)
+}
+
+// VerifyCeilingHeightAgreement verify ceiling height agreement.
+func (s *EvaluationsContract) VerifyCeilingHeightAgreement(_ contractapi.TransactionContextInterface, a *Agreement, data *EvaluationData) (*EvaluationResult, error) {
+	if data.Code != AgreementItemCodeRoomDesignCeilingHeight {
+		return nil, fmt.Errorf("evaluation data code %s is not ceiling height code", data.Code)
+	}
+
+	return verifyRoomDesignData(a, data)
+}
+
+// VerifySuiteRoomsAgreement verify number of rooms in suite agreement.
+func (s *EvaluationsContract) VerifySuiteRoomsAgreement(_ contractapi.TransactionContextInterface, a *Agreement, data *EvaluationData) (*EvaluationResult, error) {
+	if data.Code != AgreementItemCodeRoomDesignSuiteRooms {
+		return nil, fmt.Errorf("evaluation data code %s is not suite rooms code", data.Code)
+	}
+
+	return verifyRoomDesignData(a, data)
+}
+
+// verifyRoomDesignData verifies room design evaluation data given to the verify transactions,
+// the measured value must be a number.
+func verifyRoomDesignData(a *Agreement, data *EvaluationData) (*EvaluationResult, error) {
+	if _, ok := data.Value.(float64); !ok {
+		return nil, fmt.Errorf("invalid evaluation data %s: value %v is not a number", data.Code, data.Value)
+	}
+
+	return verifyRoomDesignMeasurement(a, payloadOf(AgreementCategoryRoomDesign, data).(*roomDesignPayload))
+}
+
+// verifyRoomDesignMeasurement compares the measured value with the agreed value in the same unit,
+// the measured value may be smaller than the agreed one by the tolerance percent.
+func verifyRoomDesignMeasurement(a *Agreement, data *roomDesignPayload) (*EvaluationResult, error) {
+	agreementItem := a.Items[0]
+	agreed, err := toBaseUnit(agreementItem.Code, agreementItem.Value, agreementItem.Unit)
+	if err != nil {
+		return nil, fmt.Errorf("invalid agreement item %s: %v", agreementItem.Code, err)
+	}
+	actual, err := toBaseUnit(data.Code, data.Value, data.Unit)
+	if err != nil {
+		return nil, fmt.Errorf("invalid evaluation data %s: %v", data.Code, err)
+	}
+
+	eResult := &EvaluationResult{
+		Satisfied: actual >= agreed*(1-agreementItem.TolerancePercent/100),
+		Score:     ratioScore(actual, agreed),
+	}
+	if !eResult.Satisfied {
+		eResult.PenaltyRules = defaultPenaltyRules(a, 0)
+		eResult.FailureReason = fmt.Sprintf("%s is %.2f but %.2f was agreed", agreementItem.Code, actual, agreed)
+	}
+
+	return eResult, nil
+}
+
+// VerifyCustomAgreement verifies custom agreement by evaluating the expression of each item
+// against the evaluation data with the same code.
+func (s *EvaluationsContract) VerifyCustomAgreement(_ contractapi.TransactionContextInterface, a *Agreement, data []*EvaluationData) (*EvaluationResult, error) {
+	return verifyCustomAgreement(a, customPayloads(payloadsOf(AgreementCategoryCustom, data)))
+}
+
+// verifyCustomAgreement verifies custom agreement against the custom term payloads.
+func verifyCustomAgreement(a *Agreement, data []*customPayload) (*EvaluationResult, error) {
+	for _, agreementItem := range a.Items {
+		var itemData *customPayload
+		for _, item := range data {
+			if item.Code == agreementItem.Code {
+				itemData = item
+				break
+			}
+		}
+		if itemData == nil {
+			return &EvaluationResult{
+				Satisfied:     false,
+				PenaltyRules:  defaultPenaltyRules(a, 0),
+				FailureReason: fmt.Sprintf("no data to evaluate term %s", agreementItem.Code),
+			}, nil
+		}
+
+		expression, err := parseExpression(agreementItem.Expression)
+		if err != nil {
+			return nil, err
+		}
+		satisfied, err := evaluateBool(expression, &expressionEnv{item: agreementItem, data: itemData})
+		if err != nil {
+			return nil, fmt.Errorf("can not evaluate term %s: %v", agreementItem.Code, err)
+		}
+		if !satisfied {
+			return &EvaluationResult{
+				Satisfied:     false,
+				PenaltyRules:  defaultPenaltyRules(a, 0),
+				FailureReason: fmt.Sprintf("term %s is not satisfied", agreementItem.Code),
+			}, nil
+		}
+	}
+
+	return &EvaluationResult{
+		Satisfied: true,
+	}, nil
+}
+
+// EnforcePenaltyRuleFromBlockChain enforces penalty rule from blockchain. Recorded evaluations do not call
+// the penalty endpoint, they announce the triggered penalty with the PenaltyRulesTriggered event and the
+// platform enforces it by evaluating this transaction on one of it's own peers, which keep the internal
+// service access key. The access key is used when no token is given.
+func (s *EvaluationsContract) EnforcePenaltyRuleFromBlockChain(ctx contractapi.TransactionContextInterface, rid, aid string, eResult *EvaluationResult, token string) error {
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	err = assertPlatformAdmin(ctx, config)
+	if err != nil {
+		return err
+	}
+	if !config.PenaltyEnforcementEnabled {
+		return fmt.Errorf("penalty enforcement is disabled")
+	}
+
+	if token == "" {
+		accessKey, err := readInternalServiceAccessKey(ctx, config)
+		if err != nil {
+			return err
+		}
+		token = accessKey.Token
+	}
+
+	enforcePenaltyRulesRequest := &EnforcePenaltyRulesRequest{
+		ReservationID: rid,
+		AgreementID:   aid,
+	}
+
+	if eResult != nil {
+		enforcePenaltyRulesRequest.PenaltyRules = eResult.PenaltyRules
+		enforcePenaltyRulesRequest.Reason = eResult.FailureReason
+	}
+
+	err = EnforcePenaltyRules(config, enforcePenaltyRulesRequest, token)
+	if err != nil {
+		return fmt.Errorf("fail to enforce penalty rule %v", err)
+	}
+
+	return nil
+}
+
+// HandleSatisfactionEvaluationEvent calculate satisfaction rate when
+// receiving satisfaction evaluation for a agreement.
+func (s *EvaluationsContract) HandleSatisfactionEvaluationEvent(ctx contractapi.TransactionContextInterface, sid, aid, eid, rid, hash, at string, satisfied, enforcePenaltyRule bool) (*Service, error) {
+	submission := &EvaluationSubmission{
+		ServiceID:          sid,
+		AgreementID:        aid,
+		EvaluationID:       eid,
+		ReservationID:      rid,
+		Hash:               hash,
+		At:                 at,
+		Satisfied:          satisfied,
+		EnforcePenaltyRule: enforcePenaltyRule,
+	}
+
+	recorder, err := newEvaluationRecorder(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	service, agreement, eResult, err := s.checkSubmission(ctx, recorder, submission)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.recordSatisfactionEvaluation(ctx, recorder, service, agreement, submission, eResult)
+	if err != nil {
+		return nil, err
+	}
+
+	err = recorder.save(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return service, nil
+}
+
+// checkSubmission checks a satisfaction evaluation and selects the penalty it triggers.
+func (s *EvaluationsContract) checkSubmission(ctx contractapi.TransactionContextInterface, recorder *evaluationRecorder, submission *EvaluationSubmission) (*Service, *Agreement, *EvaluationResult, error) {
+	service, agreement, err := s.checkSatisfactionEvaluation(ctx, recorder, submission)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	eResult, err := satisfactionResult(recorder, service, agreement, submission)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return service, agreement, eResult, nil
+}
+
+// checkSatisfactionEvaluation checks that a satisfaction evaluation can be recorded and returns the
+// service and agreement it evaluates.
+func (s *EvaluationsContract) checkSatisfactionEvaluation(ctx contractapi.TransactionContextInterface, recorder *evaluationRecorder, submission *EvaluationSubmission) (*Service, *Agreement, error) {
+	sid, aid, rid := submission.ServiceID, submission.AgreementID, submission.ReservationID
+
+	service, agreement, err := recorder.agreement(ctx, sid, aid)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if rid == "" {
+		return nil, nil, fmt.Errorf("the reservation id must not be empty")
+	}
+
+	evaluated, err := s.HasReservationEvaluatedAgreement(ctx, sid, rid, aid)
+	if err != nil {
+		return nil, nil, err
+	}
+	if evaluated || recorder.feedbacks[feedbackKey(sid, rid, aid)] {
+		return nil, nil, fmt.Errorf("the reservation %s has already evaluated the agreement %s", rid, aid)
+	}
+	if recorder.evaluations[submission.EvaluationID] {
+		return nil, nil, fmt.Errorf("the evaluation %s is submitted more than once", submission.EvaluationID)
+	}
+	exist, err := evaluationExists(ctx, submission.EvaluationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if exist {
+		return nil, nil, fmt.Errorf("the evaluation %s already exists", submission.EvaluationID)
+	}
+
+	// the compensation of a unsatisfied evaluation is created from the evaluation time and id
+	_, err = ParseTime(submission.At)
+	if err != nil {
+		return nil, nil, fmt.Errorf("can not parse evaluation time %s: %v", submission.At, err)
+	}
+	exist, err = s.CompensationExists(ctx, submission.EvaluationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if exist {
+		return nil, nil, fmt.Errorf("the compensation %s already exists", submission.EvaluationID)
+	}
+
+	return service, agreement, nil
+}
+
+// satisfactionResult returns the result of a satisfaction evaluation with the penalty it triggers.
+// It writes nothing, so a submission whose penalty can not be selected is rejected before it is recorded.
+func satisfactionResult(recorder *evaluationRecorder, service *Service, agreement *Agreement, submission *EvaluationSubmission) (*EvaluationResult, error) {
+	eResult := &EvaluationResult{
+		Satisfied: submission.Satisfied,
+	}
+	if submission.Satisfied {
+		eResult.Score = 1
+	}
+
+	if !submission.Satisfied && agreement.HasPenaltyRule && submission.EnforcePenaltyRule && len(agreement.PenaltyRules) > 0 {
+		eResult.PenaltyRules = defaultPenaltyRules(agreement, 0)
+		eResult.RepeatOffenses = agreement.TotalUnsatisfied
+
+		err := applyPenaltyRules(recorder.catalogue, agreement, service.ViewRanking, eResult)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return eResult, nil
+}
+
+// recordSatisfactionEvaluation records a checked satisfaction evaluation with it's result and requests
+// the enforcement of the penalty it triggers. The caller saves the service.
+func (s *EvaluationsContract) recordSatisfactionEvaluation(ctx contractapi.TransactionContextInterface, recorder *evaluationRecorder, service *Service, agreement *Agreement, submission *EvaluationSubmission, eResult *EvaluationResult) error {
+	if !submission.Satisfied && agreement.HasPenaltyRule && submission.EnforcePenaltyRule && recorder.config.PenaltyEnforcementEnabled && len(eResult.PenaltyRules) > 0 {
+		recorder.enforce(&EnforcePenaltyRulesRequest{
+			EvaluationID:  submission.EvaluationID,
+			ReservationID: submission.ReservationID,
+			AgreementID:   submission.AgreementID,
+			PenaltyRules:  eResult.PenaltyRules,
+			Reason:        eResult.FailureReason,
+		})
+	}
+
+	return recorder.record(ctx, service, agreement, &evaluationRecord{
+		evaluationType: EvaluationTypeSatisfaction,
+		eid:            submission.EvaluationID,
+		rid:            submission.ReservationID,
+		hash:           submission.Hash,
+		at:             submission.At,
+		result:         eResult,
+	})
+}
+
+// HasReservationEvaluatedAgreement returns true when the reservation has already
+// submitted a satisfaction feedback for the agreement of the service. Agreement ids
+// are only unique within a service, so the marker is kept per service.
+func (s *EvaluationsContract) HasReservationEvaluatedAgreement(ctx contractapi.TransactionContextInterface, sid, rid, aid string) (bool, error) {
+	feedbackIndexKey, err := ctx.GetStub().CreateCompositeKey(feedbackIndex, []string{sid, rid, aid})
+	if err != nil {
+		return false, err
+	}
+
+	marker, err := ctx.GetStub().GetState(feedbackIndexKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to read feedback marker from world state: %v", err)
+	}
+
+	return marker != nil, nil
+}
+
+// HandlePenaltyRuleEvaluationEvent calculate rule-abiding rate, the compensation of the evaluation
+// is settled as UpdateRuleAbidingRate does.
+func (s *EvaluationsContract) HandlePenaltyRuleEvaluationEvent(ctx contractapi.TransactionContextInterface, sid, aid, eid, hash, at string, compensated bool) (*Service, error) {
+	return s.recordRuleAbidingEvaluation(ctx, sid, aid, eid, hash, compensated)
+}
+
+// ReadEvaluation returns the evaluation stored in the world state with given id.
+func (s *EvaluationsContract) ReadEvaluation(ctx contractapi.TransactionContextInterface, eid string) (*Evaluation, error) {
+	jEvaluation, err := ctx.GetStub().GetState(eid)
+	if err != nil {
+		return nil, err
+	}
+	if jEvaluation == nil {
+		return nil, fmt.Errorf("the evaluation %s does not exist", eid)
+	}
+
+	var evaluation Evaluation
+	err = json.Unmarshal(jEvaluation, &evaluation)
+	if err != nil {
+		return nil, err
+	}
+
+	return &evaluation, nil
+}
+
+// evaluationExists returns true when the key of the evaluation is used in the world state.
+func evaluationExists(ctx contractapi.TransactionContextInterface, eid string) (bool, error) {
+	exist, err := ctx.GetStub().GetState(eid)
+	if err != nil {
+		return false, fmt.Errorf("failed to read evaluation from world state: %v", err)
+	}
+
+	return exist != nil, nil
+}
+
+// CountAllEvaluations returns number of evaluations.
+func (s *EvaluationsContract) CountAllEvaluations(ctx contractapi.TransactionContextInterface, pageSize int) (int32, error) {
+	evaluationResultsIterator, responseMetadata, err := ctx.GetStub().GetQueryResultWithPagination(`{"selector":{"docType":"Evaluation"}}`, int32(pageSize), "")
+	if err != nil {
+		return -1, fmt.Errorf("failed to count number of evaluations: %v", err)
+	}
+
+	defer evaluationResultsIterator.Close()
+
+	return responseMetadata.FetchedRecordsCount, nil
+}
@@ -0,0 +1,437 @@
+package smartcontract
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCombineItemResults(t *testing.T) {
+	discount := []*PenaltyRule{{Type: PenaltyRuleTypeDiscount, DiscountPercent: 10}}
+	passed := func() *EvaluationResult { return &EvaluationResult{Satisfied: true} }
+	failed := func(score float32, reason string) *EvaluationResult {
+		return &EvaluationResult{Score: score, FailureReason: reason, PenaltyRules: discount}
+	}
+	items := func(weights ...float64) []*AgreementItem {
+		var agreementItems []*AgreementItem
+		for _, weight := range weights {
+			agreementItems = append(agreementItems, &AgreementItem{Code: AgreementItemCodeServiceWifi, Weight: weight})
+		}
+		return agreementItems
+	}
+
+	tests := []struct {
+		name        string
+		configItem  string
+		agreement   *Agreement
+		itemResults []*EvaluationResult
+
+		satisfied bool
+		score     float32
+		severity  string
+		reason    string
+	}{
+		{
+			name:        "all passed",
+			agreement:   &Agreement{Items: items(1, 1)},
+			itemResults: []*EvaluationResult{passed(), passed()},
+			satisfied:   true,
+			score:       1,
+		},
+		{
+			name:        "all with a failed item",
+			agreement:   &Agreement{Items: items(1, 1)},
+			itemResults: []*EvaluationResult{passed(), failed(0.6, "slow")},
+			score:       0.8,
+			severity:    PenaltySeverityMinor,
+			reason:      "slow",
+		},
+		{
+			name:        "majority passed",
+			agreement:   &Agreement{Items: items(1, 1, 1), ItemPolicy: ItemPolicyMajority},
+			itemResults: []*EvaluationResult{passed(), passed(), failed(0, "down")},
+			satisfied:   true,
+			score:       float32(2) / 3,
+		},
+		{
+			name:        "majority needs more than half",
+			agreement:   &Agreement{Items: items(1, 1), ItemPolicy: ItemPolicyMajority},
+			itemResults: []*EvaluationResult{passed(), failed(0, "down")},
+			score:       0.5,
+			severity:    PenaltySeverityCritical,
+			reason:      "down",
+		},
+		{
+			name:        "weighted reaches the threshold",
+			agreement:   &Agreement{Items: items(3, 1), ItemPolicy: ItemPolicyWeighted, ItemPassThreshold: 0.75},
+			itemResults: []*EvaluationResult{passed(), failed(0, "down")},
+			satisfied:   true,
+			score:       0.75,
+		},
+		{
+			name:        "weighted below the threshold",
+			agreement:   &Agreement{Items: items(1, 3), ItemPolicy: ItemPolicyWeighted, ItemPassThreshold: 0.75},
+			itemResults: []*EvaluationResult{passed(), failed(0.2, "slow")},
+			score:       0.4,
+			severity:    PenaltySeverityMajor,
+			reason:      "slow",
+		},
+		{
+			name:        "weighted with the configured threshold",
+			agreement:   &Agreement{Items: items(0, 0), ItemPolicy: ItemPolicyWeighted},
+			itemResults: []*EvaluationResult{passed(), failed(0, "down")},
+			satisfied:   true,
+			score:       0.5,
+		},
+		{
+			name:        "configured policy",
+			configItem:  ItemPolicyMajority,
+			agreement:   &Agreement{Items: items(1, 1, 1)},
+			itemResults: []*EvaluationResult{passed(), passed(), failed(0, "down")},
+			satisfied:   true,
+			score:       float32(2) / 3,
+		},
+		{
+			name:        "agreement policy over configured policy",
+			configItem:  ItemPolicyMajority,
+			agreement:   &Agreement{Items: items(1, 1, 1), ItemPolicy: ItemPolicyAll},
+			itemResults: []*EvaluationResult{passed(), passed(), failed(0, "down")},
+			score:       float32(2) / 3,
+			severity:    PenaltySeverityCritical,
+			reason:      "down",
+		},
+		{
+			name:        "most severe failure and every reason",
+			agreement:   &Agreement{Items: items(1, 1, 1)},
+			itemResults: []*EvaluationResult{failed(0.6, "slow"), failed(0, "down"), failed(0.7, "slow")},
+			score:       float32(1.3) / 3,
+			severity:    PenaltySeverityCritical,
+			reason:      "slow; down",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := defaultConfig()
+			if tt.configItem != "" {
+				config.ItemPolicy = tt.configItem
+			}
+
+			eResult := combineItemResults(config, tt.agreement, tt.itemResults)
+			if eResult.Satisfied != tt.satisfied {
+				t.Errorf("satisfied = %v, want %v", eResult.Satisfied, tt.satisfied)
+			}
+			if !floatEqual(eResult.Score, tt.score) {
+				t.Errorf("score = %v, want %v", eResult.Score, tt.score)
+			}
+			if eResult.Severity != tt.severity {
+				t.Errorf("severity = %q, want %q", eResult.Severity, tt.severity)
+			}
+			if eResult.FailureReason != tt.reason {
+				t.Errorf("failure reason = %q, want %q", eResult.FailureReason, tt.reason)
+			}
+			if tt.satisfied == (eResult.PenaltyRules != nil) {
+				t.Errorf("penalty rules = %v, want penalty rules only when unsatisfied", eResult.PenaltyRules)
+			}
+			if len(eResult.Items) != len(tt.itemResults) {
+				t.Errorf("item results = %d, want %d", len(eResult.Items), len(tt.itemResults))
+			}
+		})
+	}
+}
+
+func floatEqual(a, b float32) bool {
+	d := a - b
+	return d < 1e-5 && d > -1e-5
+}
+
+func TestScores(t *testing.T) {
+	tests := []struct {
+		name  string
+		score float32
+		want  float32
+	}{
+		{name: "ratio delivered", score: ratioScore(30, 30), want: 1},
+		{name: "ratio over delivered", score: ratioScore(40, 30), want: 1},
+		{name: "ratio partly delivered", score: ratioScore(15, 30), want: 0.5},
+		{name: "ratio nothing delivered", score: ratioScore(0, 30), want: 0},
+		{name: "ratio nothing agreed", score: ratioScore(0, 0), want: 1},
+		{name: "delay within short wait", score: delayScore(5, 5, 15), want: 1},
+		{name: "delay between waits", score: delayScore(10, 5, 15), want: 0.75},
+		{name: "delay at long wait", score: delayScore(15, 5, 15), want: 0.5},
+		{name: "delay over long wait", score: delayScore(22.5, 5, 15), want: 0.25},
+		{name: "delay at twice long wait", score: delayScore(30, 5, 15), want: 0},
+		{name: "satisfied without score", score: resultScore(&EvaluationResult{Satisfied: true}), want: 1},
+		{name: "satisfied with score", score: resultScore(&EvaluationResult{Satisfied: true, Score: 0.8}), want: 0.8},
+		{name: "unsatisfied without score", score: resultScore(&EvaluationResult{}), want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !floatEqual(tt.score, tt.want) {
+				t.Errorf("score = %v, want %v", tt.score, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeverityFromScore(t *testing.T) {
+	tests := []struct {
+		score float32
+		want  string
+	}{
+		{score: 0.9, want: PenaltySeverityMinor},
+		{score: 0.5, want: PenaltySeverityMinor},
+		{score: 0.4, want: PenaltySeverityMajor},
+		{score: 0, want: PenaltySeverityCritical},
+	}
+
+	for _, tt := range tests {
+		if got := severityFromScore(tt.score); got != tt.want {
+			t.Errorf("severity of score %v = %s, want %s", tt.score, got, tt.want)
+		}
+	}
+}
+
+func TestRefreshSatisfactionRate(t *testing.T) {
+	scores := []struct {
+		satisfied bool
+		score     float32
+	}{{true, 1}, {false, 0.5}, {false, 0}, {true, 1}}
+
+	tests := []struct {
+		name       string
+		strategy   string
+		configRate string
+		windowSize int
+		want       float32
+	}{
+		{name: "count", want: 0.5},
+		{name: "score", strategy: RateStrategyScore, want: 0.625},
+		{name: "configured score", configRate: RateStrategyScore, want: 0.625},
+		{name: "agreement strategy over configured", strategy: RateStrategyCount, configRate: RateStrategyScore, want: 0.5},
+		{name: "count in window", windowSize: 3, want: float32(1) / 3},
+		{name: "score in window", strategy: RateStrategyScore, windowSize: 2, want: 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := defaultConfig()
+			config.RateWindowSize = tt.windowSize
+			if tt.configRate != "" {
+				config.RateStrategy = tt.configRate
+			}
+
+			agreement := &Agreement{AgreementID: "agreement1", SatisfactionRateStrategy: tt.strategy}
+			other := &Agreement{AgreementID: "agreement2", SatisfactionRate: 0.9}
+			service := &Service{Agreements: []*Agreement{other, agreement}}
+			for i, s := range scores {
+				agreement.TotalFeedbacks++
+				if !s.satisfied {
+					agreement.TotalUnsatisfied++
+				}
+				recordScore(config, agreement, string(rune('a'+i)), s.satisfied, s.score)
+				refreshSatisfactionRate(config, service, agreement)
+			}
+
+			if !floatEqual(agreement.AverageScore, 0.625) {
+				t.Errorf("average score = %v, want 0.625", agreement.AverageScore)
+			}
+			if tt.windowSize > 0 && len(agreement.RecentEvaluations) != tt.windowSize {
+				t.Errorf("rate window = %d evaluations, want %d", len(agreement.RecentEvaluations), tt.windowSize)
+			}
+			if !floatEqual(agreement.SatisfactionRate, tt.want) {
+				t.Errorf("satisfaction rate = %v, want %v", agreement.SatisfactionRate, tt.want)
+			}
+			if service.SatisfactionRate != agreement.SatisfactionRate {
+				t.Errorf("service satisfaction rate = %v, want the minimum %v", service.SatisfactionRate, agreement.SatisfactionRate)
+			}
+		})
+	}
+}
+
+func TestVerifyRoomDesignAgreement(t *testing.T) {
+	s := &EvaluationsContract{}
+	agreement := func(code string, value float64, unit string, tolerance float64) *Agreement {
+		return &Agreement{
+			Category:       AgreementCategoryRoomDesign,
+			Items:          []*AgreementItem{{Code: code, Value: value, Unit: unit, TolerancePercent: tolerance}},
+			HasPenaltyRule: true,
+			PenaltyRules:   []*PenaltyRule{{Type: PenaltyRuleTypeDiscount, DiscountPercent: 10}},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		agreement *Agreement
+		data      *EvaluationData
+		wantErr   bool
+		satisfied bool
+		score     float32
+	}{
+		{
+			name:      "size in base unit",
+			agreement: agreement(AgreementItemCodeRoomDesignSize, 30, "", 0),
+			data:      &EvaluationData{Code: AgreementItemCodeRoomDesignSize, Value: float64(30)},
+			satisfied: true,
+			score:     1,
+		},
+		{
+			name:      "size in square feet",
+			agreement: agreement(AgreementItemCodeRoomDesignSize, 30, UnitSquareMeter, 0),
+			data:      &EvaluationData{Code: AgreementItemCodeRoomDesignSize, Value: float64(330), Unit: UnitSquareFoot},
+			satisfied: true,
+			score:     1,
+		},
+		{
+			name:      "size smaller than agreed",
+			agreement: agreement(AgreementItemCodeRoomDesignSize, 30, UnitSquareMeter, 0),
+			data:      &EvaluationData{Code: AgreementItemCodeRoomDesignSize, Value: float64(27)},
+			score:     0.9,
+		},
+		{
+			name:      "size within tolerance",
+			agreement: agreement(AgreementItemCodeRoomDesignSize, 30, UnitSquareMeter, 10),
+			data:      &EvaluationData{Code: AgreementItemCodeRoomDesignSize, Value: float64(27)},
+			satisfied: true,
+			score:     0.9,
+		},
+		{
+			name:      "ceiling height in feet",
+			agreement: agreement(AgreementItemCodeRoomDesignCeilingHeight, 10, UnitFoot, 0),
+			data:      &EvaluationData{Code: AgreementItemCodeRoomDesignCeilingHeight, Value: float64(3.048), Unit: UnitMeter},
+			satisfied: true,
+			score:     1,
+		},
+		{
+			name:      "ceiling height lower than agreed",
+			agreement: agreement(AgreementItemCodeRoomDesignCeilingHeight, 3, UnitMeter, 0),
+			data:      &EvaluationData{Code: AgreementItemCodeRoomDesignCeilingHeight, Value: float64(1.5)},
+			score:     0.5,
+		},
+		{
+			name:      "suite rooms",
+			agreement: agreement(AgreementItemCodeRoomDesignSuiteRooms, 2, "", 0),
+			data:      &EvaluationData{Code: AgreementItemCodeRoomDesignSuiteRooms, Value: float64(1)},
+			score:     0.5,
+		},
+		{
+			name:      "suite rooms have no unit",
+			agreement: agreement(AgreementItemCodeRoomDesignSuiteRooms, 2, "", 0),
+			data:      &EvaluationData{Code: AgreementItemCodeRoomDesignSuiteRooms, Value: float64(2), Unit: UnitMeter},
+			wantErr:   true,
+		},
+		{
+			name:      "unit of another code",
+			agreement: agreement(AgreementItemCodeRoomDesignSize, 30, UnitSquareMeter, 0),
+			data:      &EvaluationData{Code: AgreementItemCodeRoomDesignSize, Value: float64(30), Unit: UnitMeter},
+			wantErr:   true,
+		},
+		{
+			name:      "value is not a number",
+			agreement: agreement(AgreementItemCodeRoomDesignSize, 30, UnitSquareMeter, 0),
+			data:      &EvaluationData{Code: AgreementItemCodeRoomDesignSize, Value: "30"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var eResult *EvaluationResult
+			var err error
+			switch tt.data.Code {
+			case AgreementItemCodeRoomDesignSize:
+				eResult, err = s.VerifyRoomSizeAgreement(nil, tt.agreement, tt.data)
+			case AgreementItemCodeRoomDesignCeilingHeight:
+				eResult, err = s.VerifyCeilingHeightAgreement(nil, tt.agreement, tt.data)
+			default:
+				eResult, err = s.VerifySuiteRoomsAgreement(nil, tt.agreement, tt.data)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if eResult.Satisfied != tt.satisfied {
+				t.Errorf("satisfied = %v, want %v", eResult.Satisfied, tt.satisfied)
+			}
+			if !floatEqual(eResult.Score, tt.score) {
+				t.Errorf("score = %v, want %v", eResult.Score, tt.score)
+			}
+			if !eResult.Satisfied && eResult.FailureReason == "" {
+				t.Errorf("unsatisfied result has no failure reason")
+			}
+		})
+	}
+}
+
+func TestValidateRoomDesignItem(t *testing.T) {
+	tests := []struct {
+		name    string
+		item    *AgreementItem
+		wantErr bool
+	}{
+		{name: "size", item: &AgreementItem{Code: AgreementItemCodeRoomDesignSize, Value: float64(30), Unit: UnitSquareFoot, TolerancePercent: 5}},
+		{name: "suite rooms", item: &AgreementItem{Code: AgreementItemCodeRoomDesignSuiteRooms, Value: float64(2)}},
+		{name: "unknown code", item: &AgreementItem{Code: AgreementItemCodeServiceWifi, Value: float64(30)}, wantErr: true},
+		{name: "unknown unit", item: &AgreementItem{Code: AgreementItemCodeRoomDesignSize, Value: float64(30), Unit: "yd2"}, wantErr: true},
+		{name: "zero value", item: &AgreementItem{Code: AgreementItemCodeRoomDesignSize, Value: float64(0)}, wantErr: true},
+		{name: "missing value", item: &AgreementItem{Code: AgreementItemCodeRoomDesignSize}, wantErr: true},
+		{name: "negative tolerance", item: &AgreementItem{Code: AgreementItemCodeRoomDesignSize, Value: float64(30), TolerancePercent: -1}, wantErr: true},
+		{name: "full tolerance", item: &AgreementItem{Code: AgreementItemCodeRoomDesignSize, Value: float64(30), TolerancePercent: 100}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRoomDesignItem(tt.item)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSimulateSLA(t *testing.T) {
+	tests := []struct {
+		name           string
+		sid            string
+		driverArriveAt string
+
+		wantErr   bool
+		satisfied bool
+		discount  float32
+	}{
+		{name: "satisfied", sid: recorderServiceID, driverArriveAt: "2021-05-01T10:05:00Z", satisfied: true},
+		{name: "unsatisfied", sid: recorderServiceID, driverArriveAt: "2021-05-01T10:20:00Z", discount: 50},
+		{name: "missing service", sid: "missing", driverArriveAt: "2021-05-01T10:05:00Z", wantErr: true},
+	}
+
+	s := &EvaluationsContract{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, stub := newTestContext(t)
+			putTestService(t, stub, shuttleService(recorderServiceID))
+			numKeys := len(stub.State)
+			jService := stub.State[recorderServiceID]
+
+			eResult, err := s.SimulateSLA(ctx, tt.sid, recorderAgreementID, shuttleEvaluationData(tt.driverArriveAt))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+
+			if len(stub.State) != numKeys || !bytes.Equal(stub.State[recorderServiceID], jService) {
+				t.Errorf("the simulation wrote to the world state")
+			}
+			if tt.wantErr {
+				return
+			}
+			if eResult.Satisfied != tt.satisfied {
+				t.Errorf("satisfied = %v, want %v", eResult.Satisfied, tt.satisfied)
+			}
+			if eResult.DiscountPercent != tt.discount {
+				t.Errorf("discount = %v, want %v", eResult.DiscountPercent, tt.discount)
+			}
+		})
+	}
+}
@@ -0,0 +1,427 @@
+package smartcontract
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Expression limits keep parsing and evaluating an agreement expression bounded.
+const (
+	maxExpressionLength = 1024
+	maxExpressionNodes  = 256
+	maxExpressionDepth  = 32
+)
+
+// Expression identifier prefixes.
+const (
+	expressionDataPrefix  = "data."
+	expressionParamPrefix = "param."
+)
+
+// exprNode is a node of a parsed agreement expression.
+type exprNode struct {
+	op          string
+	value       interface{}
+	name        string
+	left, right *exprNode
+}
+
+// exprToken is a lexical token of an agreement expression.
+type exprToken struct {
+	kind  string // number, string, ident, op
+	text  string
+	value interface{}
+}
+
+// parseExpression parses an agreement expression such as
+// "data.bandwidth >= param.minBandwidth && data.status == 'up'".
+func parseExpression(src string) (*exprNode, error) {
+	if len(src) > maxExpressionLength {
+		return nil, fmt.Errorf("expression is longer than %d characters", maxExpressionLength)
+	}
+
+	tokens, err := tokenizeExpression(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &exprParser{tokens: tokens}
+	node, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected token %q in expression", p.tokens[p.pos].text)
+	}
+
+	return node, nil
+}
+
+func tokenizeExpression(src string) ([]exprToken, error) {
+	var tokens []exprToken
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(src) && (src[j] >= '0' && src[j] <= '9' || src[j] == '.') {
+				j++
+			}
+			number, err := strconv.ParseFloat(src[i:j], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %s in expression", src[i:j])
+			}
+			tokens = append(tokens, exprToken{kind: "number", text: src[i:j], value: number})
+			i = j
+		case c == '\'' || c == '"':
+			j := strings.IndexByte(src[i+1:], c)
+			if j < 0 {
+				return nil, fmt.Errorf("unterminated string in expression")
+			}
+			text := src[i+1 : i+1+j]
+			tokens = append(tokens, exprToken{kind: "string", text: text, value: text})
+			i += j + 2
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			j := i
+			for j < len(src) && (src[j] == '_' || src[j] == '.' || src[j] >= 'a' && src[j] <= 'z' ||
+				src[j] >= 'A' && src[j] <= 'Z' || src[j] >= '0' && src[j] <= '9') {
+				j++
+			}
+			text := src[i:j]
+			switch text {
+			case "true", "false":
+				tokens = append(tokens, exprToken{kind: "bool", text: text, value: text == "true"})
+			default:
+				tokens = append(tokens, exprToken{kind: "ident", text: text})
+			}
+			i = j
+		default:
+			op := ""
+			for _, candidate := range []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "+", "-", "*", "/", "%", "!", "(", ")"} {
+				if strings.HasPrefix(src[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q in expression", c)
+			}
+			tokens = append(tokens, exprToken{kind: "op", text: op})
+			i += len(op)
+		}
+	}
+
+	return tokens, nil
+}
+
+// exprParser is a recursive descent parser of agreement expressions.
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+	nodes  int
+}
+
+func (p *exprParser) newNode(node *exprNode) (*exprNode, error) {
+	p.nodes++
+	if p.nodes > maxExpressionNodes {
+		return nil, fmt.Errorf("expression has more than %d nodes", maxExpressionNodes)
+	}
+	return node, nil
+}
+
+func (p *exprParser) accept(ops ...string) (string, bool) {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != "op" {
+		return "", false
+	}
+	for _, op := range ops {
+		if p.tokens[p.pos].text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *exprParser) parseBinary(depth int, next func(int) (*exprNode, error), ops ...string) (*exprNode, error) {
+	left, err := next(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op, ok := p.accept(ops...)
+		if !ok {
+			return left, nil
+		}
+		right, err := next(depth)
+		if err != nil {
+			return nil, err
+		}
+		left, err = p.newNode(&exprNode{op: op, left: left, right: right})
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *exprParser) parseOr(depth int) (*exprNode, error) {
+	if depth > maxExpressionDepth {
+		return nil, fmt.Errorf("expression is nested deeper than %d levels", maxExpressionDepth)
+	}
+	return p.parseBinary(depth, p.parseAnd, "||")
+}
+
+func (p *exprParser) parseAnd(depth int) (*exprNode, error) {
+	return p.parseBinary(depth, p.parseComparison, "&&")
+}
+
+func (p *exprParser) parseComparison(depth int) (*exprNode, error) {
+	left, err := p.parseAdditive(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	op, ok := p.accept("==", "!=", "<=", ">=", "<", ">")
+	if !ok {
+		return left, nil
+	}
+	right, err := p.parseAdditive(depth)
+	if err != nil {
+		return nil, err
+	}
+	return p.newNode(&exprNode{op: op, left: left, right: right})
+}
+
+func (p *exprParser) parseAdditive(depth int) (*exprNode, error) {
+	return p.parseBinary(depth, p.parseMultiplicative, "+", "-")
+}
+
+func (p *exprParser) parseMultiplicative(depth int) (*exprNode, error) {
+	return p.parseBinary(depth, p.parseUnary, "*", "/", "%")
+}
+
+func (p *exprParser) parseUnary(depth int) (*exprNode, error) {
+	if op, ok := p.accept("!", "-"); ok {
+		if depth+1 > maxExpressionDepth {
+			return nil, fmt.Errorf("expression is nested deeper than %d levels", maxExpressionDepth)
+		}
+		operand, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return p.newNode(&exprNode{op: op, left: operand})
+	}
+	return p.parsePrimary(depth)
+}
+
+func (p *exprParser) parsePrimary(depth int) (*exprNode, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+
+	if _, ok := p.accept("("); ok {
+		node, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := p.accept(")"); !ok {
+			return nil, fmt.Errorf("missing closing parenthesis in expression")
+		}
+		return node, nil
+	}
+
+	token := p.tokens[p.pos]
+	switch token.kind {
+	case "number", "string", "bool":
+		p.pos++
+		return p.newNode(&exprNode{op: "literal", value: token.value})
+	case "ident":
+		if !strings.HasPrefix(token.text, expressionDataPrefix) && !strings.HasPrefix(token.text, expressionParamPrefix) {
+			return nil, fmt.Errorf("identifier %s must start with %s or %s", token.text, expressionDataPrefix, expressionParamPrefix)
+		}
+		p.pos++
+		return p.newNode(&exprNode{op: "ident", name: token.text})
+	default:
+		return nil, fmt.Errorf("unexpected token %q in expression", token.text)
+	}
+}
+
+// expressionEnv resolves identifiers of an agreement expression against the payload of a custom term.
+type expressionEnv struct {
+	item *AgreementItem
+	data *customPayload
+}
+
+func (env *expressionEnv) resolve(name string) (interface{}, error) {
+	if strings.HasPrefix(name, expressionParamPrefix) {
+		key := strings.TrimPrefix(name, expressionParamPrefix)
+		value, ok := env.item.Parameters[key]
+		if !ok {
+			return nil, fmt.Errorf("agreement parameter %s does not exist", key)
+		}
+		return normalizeExpressionValue(name, value)
+	}
+
+	key := strings.TrimPrefix(name, expressionDataPrefix)
+	d := env.data
+	switch key {
+	case "code":
+		return d.Code, nil
+	case "name":
+		return d.Name, nil
+	case "quantity":
+		return float64(d.Quantity), nil
+	case "status":
+		return d.Status, nil
+	case "fulfilled":
+		return d.Fulfilled, nil
+	case "value":
+		return normalizeExpressionValue(name, d.Value)
+	}
+
+	value, ok := d.Metrics[key]
+	if !ok {
+		return nil, fmt.Errorf("evaluation data %s does not exist", key)
+	}
+	return normalizeExpressionValue(name, value)
+}
+
+func normalizeExpressionValue(name string, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case float64, string, bool:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	default:
+		return nil, fmt.Errorf("%s is not a number, string or boolean", name)
+	}
+}
+
+// evaluateExpression evaluates a parsed agreement expression.
+func evaluateExpression(node *exprNode, env *expressionEnv) (interface{}, error) {
+	switch node.op {
+	case "literal":
+		return node.value, nil
+	case "ident":
+		return env.resolve(node.name)
+	case "&&", "||":
+		left, err := evaluateBool(node.left, env)
+		if err != nil {
+			return nil, err
+		}
+		if node.op == "&&" && !left || node.op == "||" && left {
+			return left, nil
+		}
+		return evaluateBool(node.right, env)
+	case "!":
+		operand, err := evaluateBool(node.left, env)
+		if err != nil {
+			return nil, err
+		}
+		return !operand, nil
+	}
+
+	if node.right == nil {
+		operand, err := evaluateNumber(node.left, env)
+		if err != nil {
+			return nil, err
+		}
+		return -operand, nil
+	}
+
+	left, err := evaluateExpression(node.left, env)
+	if err != nil {
+		return nil, err
+	}
+	right, err := evaluateExpression(node.right, env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch node.op {
+	case "==":
+		return left == right, nil
+	case "!=":
+		return left != right, nil
+	}
+
+	if l, ok := left.(string); ok {
+		r, ok := right.(string)
+		if !ok {
+			return nil, fmt.Errorf("can not apply %s to string and %v", node.op, right)
+		}
+		switch node.op {
+		case "<":
+			return l < r, nil
+		case "<=":
+			return l <= r, nil
+		case ">":
+			return l > r, nil
+		case ">=":
+			return l >= r, nil
+		}
+		return nil, fmt.Errorf("can not apply %s to strings", node.op)
+	}
+
+	l, lok := left.(float64)
+	r, rok := right.(float64)
+	if !lok || !rok {
+		return nil, fmt.Errorf("can not apply %s to %v and %v", node.op, left, right)
+	}
+	switch node.op {
+	case "<":
+		return l < r, nil
+	case "<=":
+		return l <= r, nil
+	case ">":
+		return l > r, nil
+	case ">=":
+		return l >= r, nil
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/", "%":
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero in expression")
+		}
+		if node.op == "%" {
+			return math.Mod(l, r), nil
+		}
+		return l / r, nil
+	}
+
+	return nil, fmt.Errorf("unknown operator %s in expression", node.op)
+}
+
+func evaluateBool(node *exprNode, env *expressionEnv) (bool, error) {
+	value, err := evaluateExpression(node, env)
+	if err != nil {
+		return false, err
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("expected a boolean but got %v", value)
+	}
+	return b, nil
+}
+
+func evaluateNumber(node *exprNode, env *expressionEnv) (float64, error) {
+	value, err := evaluateExpression(node, env)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := value.(float64)
+	if !ok {
+		return 0, fmt.Errorf("expected a number but got %v", value)
+	}
+	return n, nil
+}
@@ -0,0 +1,112 @@
+package smartcontract
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEvaluateExpression(t *testing.T) {
+	env := &expressionEnv{
+		item: &AgreementItem{Parameters: map[string]interface{}{"minBandwidth": 50, "status": "up"}},
+		data: &customPayload{Status: "up", Metrics: map[string]interface{}{"bandwidth": float32(80), "list": []int{1}}},
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "multiplication before addition", expression: "1 + 2 * 3", want: float64(7)},
+		{name: "parentheses", expression: "(1 + 2) * 3", want: float64(9)},
+		{name: "left associative subtraction", expression: "10 - 4 - 3", want: float64(3)},
+		{name: "left associative division", expression: "24 / 4 / 2", want: float64(3)},
+		{name: "modulo", expression: "7 % 4", want: float64(3)},
+		{name: "unary minus", expression: "-2 * 3", want: float64(-6)},
+		{name: "comparison before and", expression: "1 < 2 && 3 > 2", want: true},
+		{name: "and before or", expression: "true || false && false", want: true},
+		{name: "or of ands", expression: "false && true || true", want: true},
+		{name: "not", expression: "!(1 == 2)", want: true},
+		{name: "string comparison", expression: "data.status == param.status", want: true},
+		{name: "string ordering", expression: "'a' < 'b'", want: true},
+		{name: "parameter and metric", expression: "data.bandwidth >= param.minBandwidth", want: true},
+		{name: "different types are not equal", expression: "1 == '1'", want: false},
+
+		{name: "and short circuits", expression: "false && data.missing > 0", want: false},
+		{name: "or short circuits", expression: "true || 1 / 0 > 0", want: true},
+		{name: "and evaluates the right operand", expression: "true && data.missing > 0", wantErr: true},
+		{name: "or evaluates the right operand", expression: "false || 1 / 0 > 0", wantErr: true},
+
+		{name: "division by zero", expression: "1 / 0", wantErr: true},
+		{name: "modulo by zero", expression: "1 % (2 - 2)", wantErr: true},
+
+		{name: "and of numbers", expression: "1 && 2", wantErr: true},
+		{name: "not of a string", expression: "!'up'", wantErr: true},
+		{name: "minus of a boolean", expression: "-true", wantErr: true},
+		{name: "number and string", expression: "1 < 'a'", wantErr: true},
+		{name: "string and number", expression: "'a' < 1", wantErr: true},
+		{name: "addition of strings", expression: "'a' + 'b'", wantErr: true},
+		{name: "unknown data", expression: "data.missing > 0", wantErr: true},
+		{name: "unknown parameter", expression: "param.missing > 0", wantErr: true},
+		{name: "unsupported value", expression: "data.list == 1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := parseExpression(tt.expression)
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+
+			got, err := evaluateExpression(node, env)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseExpression(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		wantErr    bool
+	}{
+		{name: "comparison", expression: "data.bandwidth >= param.minBandwidth && data.status == 'up'"},
+		{name: "longest expression", expression: strings.Repeat(" ", maxExpressionLength-1) + "1"},
+		{name: "too long", expression: strings.Repeat(" ", maxExpressionLength) + "1", wantErr: true},
+		{name: "most nodes", expression: "1" + strings.Repeat("+1", (maxExpressionNodes-1)/2)},
+		{name: "too many nodes", expression: "1" + strings.Repeat("+1", maxExpressionNodes/2), wantErr: true},
+		{name: "deepest nesting", expression: strings.Repeat("(", maxExpressionDepth) + "1" + strings.Repeat(")", maxExpressionDepth)},
+		{name: "too deep nesting", expression: strings.Repeat("(", maxExpressionDepth+1) + "1" + strings.Repeat(")", maxExpressionDepth+1), wantErr: true},
+		{name: "too deep unary", expression: strings.Repeat("!", maxExpressionDepth+1) + "true", wantErr: true},
+
+		{name: "empty", expression: "", wantErr: true},
+		{name: "blank", expression: "  ", wantErr: true},
+		{name: "open parenthesis", expression: "(", wantErr: true},
+		{name: "missing closing parenthesis", expression: "(1 + 2", wantErr: true},
+		{name: "extra closing parenthesis", expression: "1 + 2)", wantErr: true},
+		{name: "missing operand", expression: "1 +", wantErr: true},
+		{name: "missing left operand", expression: "&& true", wantErr: true},
+		{name: "chained comparison", expression: "1 < 2 < 3", wantErr: true},
+		{name: "invalid number", expression: "1.2.3", wantErr: true},
+		{name: "unterminated string", expression: "data.status == 'up", wantErr: true},
+		{name: "unexpected character", expression: "1 # 2", wantErr: true},
+		{name: "single ampersand", expression: "true & false", wantErr: true},
+		{name: "identifier without prefix", expression: "bandwidth > 1", wantErr: true},
+		{name: "adjacent operands", expression: "1 2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseExpression(tt.expression)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"net/http"
 	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
 
 // EnforcePenaltyRulesRequest represents for enforcing penalty rules request.
@@ -17,15 +19,20 @@ type EnforcePenaltyRulesRequest struct {
 	Reason        string         `json:"reason"`
 }
 
-// EnforcePenaltyRules enforces penalty rules.
-func EnforcePenaltyRules(req *EnforcePenaltyRulesRequest, token string) error {
+// PenaltyRulesTriggeredEvent represents for the penalties triggered in a transaction.
+type PenaltyRulesTriggeredEvent struct {
+	TxID     string                        `json:"txId"`
+	Requests []*EnforcePenaltyRulesRequest `json:"requests"`
+}
+
+// EnforcePenaltyRules enforces penalty rules at the penalty endpoint of the configuration.
+func EnforcePenaltyRules(config *ContractConfig, req *EnforcePenaltyRulesRequest, token string) error {
 	bodyReq, err := json.Marshal(req)
 	if err != nil {
 		return err
 	}
 
-	url := BaseURL + "/rpc/agreements/enforce-penalty-rules"
-	httpReq, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(bodyReq))
+	httpReq, err := http.NewRequest(http.MethodPost, config.PenaltyEndpoint, bytes.NewBuffer(bodyReq))
 	if err != nil {
 		return err
 	}
@@ -34,7 +41,7 @@ func EnforcePenaltyRules(req *EnforcePenaltyRulesRequest, token string) error {
 	httpReq.Header.Set("Accept", "application/json")
 	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 
-	timeout := 60 * time.Second
+	timeout := time.Duration(config.PenaltyRequestTimeoutSeconds) * time.Second
 	client := &http.Client{Timeout: timeout}
 	httpRes, err := client.Do(httpReq)
 	if err != nil {
@@ -69,3 +76,342 @@ func ParseTime(s string) (time.Time, error) {
 func MakeErrorAgreementItemCodeDoesNotSupport(code, cat string) error {
 	return fmt.Errorf("agreement item code %s in category %s as not supported yet", code, cat)
 }
+
+// validateAgreementItems validates items of a agreement in the given category against the catalogue.
+func validateAgreementItems(c *catalogue, cat string, items []*AgreementItem) error {
+	if !c.hasCategory(cat) {
+		return fmt.Errorf("agreement category %s has not supported yet", cat)
+	}
+
+	for _, item := range items {
+		if cat != AgreementCategoryCustom && !c.hasItem(cat, item.Code) {
+			return MakeErrorAgreementItemCodeDoesNotSupport(item.Code, cat)
+		}
+		if !hasItemVerifier(cat, item.Code) {
+			return MakeErrorAgreementItemCodeDoesNotSupport(item.Code, cat)
+		}
+
+		if cat == AgreementCategoryRoomDesign {
+			if err := validateRoomDesignItem(item); err != nil {
+				return err
+			}
+		}
+		if cat == AgreementCategoryView {
+			if err := validateViewItem(c, item); err != nil {
+				return err
+			}
+		}
+		if cat == AgreementCategoryBed {
+			if item.Quantity < 0 {
+				return fmt.Errorf("quantity of agreement item %s must not be negative", item.Code)
+			}
+		}
+		if cat == AgreementCategoryService {
+			if err := validateServiceItem(item); err != nil {
+				return err
+			}
+		}
+
+		if cat != AgreementCategoryCustom {
+			if item.Expression != "" {
+				return fmt.Errorf("only items in category %s can have an expression", AgreementCategoryCustom)
+			}
+			continue
+		}
+
+		if item.Expression == "" {
+			return fmt.Errorf("agreement item %s in category %s must have an expression", item.Code, cat)
+		}
+		if _, err := parseExpression(item.Expression); err != nil {
+			return fmt.Errorf("invalid expression of agreement item %s: %v", item.Code, err)
+		}
+	}
+
+	return nil
+}
+
+// validateServiceItem validates the thresholds of a service item.
+func validateServiceItem(item *AgreementItem) error {
+	switch item.Code {
+	case AgreementItemCodeServiceSauna:
+		return validateSaunaItem(item)
+	case AgreementItemCodeServiceBreakfast:
+		return validateBreakfastItem(item)
+	case AgreementItemCodeServiceHousekeeping:
+		return validateHousekeepingItem(item)
+	case AgreementItemCodeServiceWifi:
+		return validateWifiItem(item)
+	case AgreementItemCodeServiceCheckIn:
+		return validateCheckInItem(item)
+	}
+
+	return nil
+}
+
+// parseClock returns minutes since midnight of a clock time in HH:MM.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse(ClockLayout, s)
+	if err != nil {
+		return 0, err
+	}
+
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// clockOf returns minutes since midnight of a time in it's own location.
+func clockOf(t time.Time) int {
+	return t.Hour()*60 + t.Minute()
+}
+
+// stay is the dates of a stay, from the start date for the given number of days.
+type stay struct {
+	start time.Time
+	days  int
+}
+
+// newStay validates the start date and the number of days of a stay.
+func newStay(startDate string, days int) (*stay, error) {
+	start, err := time.Parse(DateLayout, startDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stay start date %s: %v", startDate, err)
+	}
+	if days <= 0 {
+		return nil, fmt.Errorf("stay days must be positive")
+	}
+
+	return &stay{start: start, days: days}, nil
+}
+
+// has returns true when the date in DateLayout is a day of the stay.
+func (st *stay) has(date string) bool {
+	t, err := time.Parse(DateLayout, date)
+	if err != nil || t.Before(st.start) {
+		return false
+	}
+
+	return int(t.Sub(st.start).Hours()/24) < st.days
+}
+
+// validateRoomDesignItem validates the code, value, unit and tolerance of a room design item.
+func validateRoomDesignItem(item *AgreementItem) error {
+	if _, ok := RoomDesignUnitFactors[item.Code]; !ok {
+		return MakeErrorAgreementItemCodeDoesNotSupport(item.Code, AgreementCategoryRoomDesign)
+	}
+
+	value, err := toBaseUnit(item.Code, item.Value, item.Unit)
+	if err != nil {
+		return fmt.Errorf("invalid agreement item %s: %v", item.Code, err)
+	}
+	if value <= 0 {
+		return fmt.Errorf("value of agreement item %s must be positive", item.Code)
+	}
+	if item.TolerancePercent < 0 || item.TolerancePercent >= 100 {
+		return fmt.Errorf("tolerance percent of agreement item %s must be between 0 and 100", item.Code)
+	}
+
+	return nil
+}
+
+// toBaseUnit converts a room design measurement to the base unit of it's code.
+func toBaseUnit(code string, value interface{}, unit string) (float64, error) {
+	number, ok := value.(float64)
+	if !ok {
+		return 0, fmt.Errorf("value %v is not a number", value)
+	}
+
+	factors := RoomDesignUnitFactors[code]
+	if len(factors) == 0 {
+		if unit != "" {
+			return 0, fmt.Errorf("code %s is a count and has no unit", code)
+		}
+		return number, nil
+	}
+
+	if unit == "" {
+		unit = RoomDesignBaseUnits[code]
+	}
+	factor, ok := factors[unit]
+	if !ok {
+		return 0, fmt.Errorf("unit %s is not supported for code %s", unit, code)
+	}
+
+	return number * factor, nil
+}
+
+// validateAgreementPolicy validates the policy of a agreement.
+func validateAgreementPolicy(p *AgreementPolicy) error {
+	err := validatePenaltyCombination(p.PenaltyCombination, p.MaxDiscountPercent, p.MaxAmount)
+	if err != nil {
+		return err
+	}
+	if p.ItemPolicy != "" && !StringInSlice(p.ItemPolicy, []string{ItemPolicyAll, ItemPolicyMajority, ItemPolicyWeighted}) {
+		return fmt.Errorf("item policy %s has not supported yet", p.ItemPolicy)
+	}
+	if p.ItemPassThreshold < 0 || p.ItemPassThreshold > 1 {
+		return fmt.Errorf("pass threshold must be between 0 and 1")
+	}
+	if p.BedMatchMode != "" && !StringInSlice(p.BedMatchMode, []string{BedMatchModeExact, BedMatchModeAtLeastEquivalent, BedMatchModeUpgradeOnly}) {
+		return fmt.Errorf("bed match mode %s has not supported yet", p.BedMatchMode)
+	}
+	if p.SatisfactionRateStrategy != "" && p.SatisfactionRateStrategy != RateStrategyCount && p.SatisfactionRateStrategy != RateStrategyScore {
+		return fmt.Errorf("satisfaction rate strategy %s has not supported yet", p.SatisfactionRateStrategy)
+	}
+
+	return nil
+}
+
+// agreementPolicyOf returns the current policy of a agreement.
+func agreementPolicyOf(a *Agreement) AgreementPolicy {
+	return AgreementPolicy{
+		PenaltyCombination:       a.PenaltyCombination,
+		MaxDiscountPercent:       a.MaxDiscountPercent,
+		MaxAmount:                a.MaxAmount,
+		ItemPolicy:               a.ItemPolicy,
+		ItemPassThreshold:        a.ItemPassThreshold,
+		BedMatchMode:             a.BedMatchMode,
+		SatisfactionRateStrategy: a.SatisfactionRateStrategy,
+	}
+}
+
+// terms returns the part of the policy which decides verdicts and penalties of the agreement.
+func (p AgreementPolicy) terms() AgreementPolicy {
+	p.SatisfactionRateStrategy = ""
+	return p
+}
+
+// findAgreement returns the agreement of a service with given id.
+func findAgreement(service *Service, aid string) (*Agreement, error) {
+	for _, a := range service.Agreements {
+		if a.AgreementID == aid {
+			return a, nil
+		}
+	}
+	return nil, fmt.Errorf("the agreement %s does not exist", aid)
+}
+
+// verdict returns the evaluation verdict for a satisfaction outcome.
+func verdict(satisfied bool) string {
+	if satisfied {
+		return EvaluationVerdictSatisfied
+	}
+	return EvaluationVerdictUnsatisfied
+}
+
+// assertRole returns an error when the submitting client does not have the given role.
+func assertRole(ctx contractapi.TransactionContextInterface, role string) error {
+	err := ctx.GetClientIdentity().AssertAttributeValue(IdentityAttributeRole, role)
+	if err != nil {
+		return fmt.Errorf("the client must have role %s: %v", role, err)
+	}
+	return nil
+}
+
+// assertAnyRole returns an error when the submitting client has none of the given roles.
+func assertAnyRole(ctx contractapi.TransactionContextInterface, roles ...string) error {
+	for _, role := range roles {
+		if ctx.GetClientIdentity().AssertAttributeValue(IdentityAttributeRole, role) == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("the client must have one of roles %v", roles)
+}
+
+// assertServiceOwner returns an error when the submitting client is not the provider of the service.
+func assertServiceOwner(ctx contractapi.TransactionContextInterface, sid string) error {
+	err := assertRole(ctx, RoleProvider)
+	if err != nil {
+		return err
+	}
+
+	err = ctx.GetClientIdentity().AssertAttributeValue(IdentityAttributeServiceID, sid)
+	if err != nil {
+		return fmt.Errorf("the client is not the provider of service %s: %v", sid, err)
+	}
+	return nil
+}
+
+// refreshRuleAbidingRate recalculates the rule-abiding rate of the agreement
+// and the minimum rule-abiding rate of the service.
+func refreshRuleAbidingRate(service *Service, agreement *Agreement) {
+	agreement.RuleAbidingRate = 1
+	if agreement.TotalRuleViolations > 0 {
+		agreement.RuleAbidingRate = float32(agreement.TotalRuleViolations-
+			agreement.TotalRuleViolationWithoutCompensations) / float32(agreement.TotalRuleViolations)
+	}
+
+	minRuleAbidingRate := service.Agreements[0].RuleAbidingRate
+	for _, a := range service.Agreements {
+		if a.RuleAbidingRate < minRuleAbidingRate {
+			minRuleAbidingRate = a.RuleAbidingRate
+		}
+	}
+	service.RuleAbidingRate = minRuleAbidingRate
+}
+
+// recordScore adds the score of a evaluation to the average score of the agreement
+// and to the rate window of the agreement.
+func recordScore(config *ContractConfig, agreement *Agreement, eid string, satisfied bool, score float32) {
+	agreement.TotalScore += float64(score)
+	agreement.ScoredFeedbacks++
+	agreement.AverageScore = float32(agreement.TotalScore / float64(agreement.ScoredFeedbacks))
+
+	if config.RateWindowSize <= 0 {
+		agreement.RecentEvaluations = nil
+		return
+	}
+	agreement.RecentEvaluations = append(agreement.RecentEvaluations, &RecentEvaluation{
+		EvaluationID: eid,
+		Satisfied:    satisfied,
+		Score:        score,
+	})
+	if len(agreement.RecentEvaluations) > config.RateWindowSize {
+		agreement.RecentEvaluations = agreement.RecentEvaluations[len(agreement.RecentEvaluations)-config.RateWindowSize:]
+	}
+}
+
+// refreshSatisfactionRate recalculates the satisfaction rate of the agreement
+// and the minimum satisfaction rate of the service. When the configuration has a rate window
+// the rate is computed over the latest evaluations recorded in it.
+func refreshSatisfactionRate(config *ContractConfig, service *Service, agreement *Agreement) {
+	strategy := agreement.SatisfactionRateStrategy
+	if strategy == "" {
+		strategy = config.RateStrategy
+	}
+
+	agreement.SatisfactionRate = 1
+	switch {
+	case config.RateWindowSize > 0 && len(agreement.RecentEvaluations) > 0:
+		agreement.SatisfactionRate = windowRate(strategy, agreement.RecentEvaluations)
+	case strategy == RateStrategyScore:
+		if agreement.ScoredFeedbacks > 0 {
+			agreement.SatisfactionRate = agreement.AverageScore
+		}
+	case agreement.TotalFeedbacks > 0:
+		agreement.SatisfactionRate = float32(agreement.TotalFeedbacks-
+			agreement.TotalUnsatisfied) / float32(agreement.TotalFeedbacks)
+	}
+
+	minSatisfactionRate := service.Agreements[0].SatisfactionRate
+	for _, a := range service.Agreements {
+		if a.SatisfactionRate < minSatisfactionRate {
+			minSatisfactionRate = a.SatisfactionRate
+		}
+	}
+	service.SatisfactionRate = minSatisfactionRate
+}
+
+// windowRate returns the satisfaction rate over the evaluations of a rate window.
+func windowRate(strategy string, evaluations []*RecentEvaluation) float32 {
+	var satisfied, score float32
+	for _, e := range evaluations {
+		if e.Satisfied {
+			satisfied++
+		}
+		score += e.Score
+	}
+
+	if strategy == RateStrategyScore {
+		return score / float32(len(evaluations))
+	}
+	return satisfied / float32(len(evaluations))
+}
@@ -0,0 +1,119 @@
+package smartcontract
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// testIdentity is a client identity with the given attributes, it belongs to the platform org unless
+// a MSP ID is given.
+type testIdentity struct {
+	id    string
+	mspID string
+	attrs map[string]string
+}
+
+func (i *testIdentity) GetID() (string, error) {
+	return i.id, nil
+}
+
+func (i *testIdentity) GetMSPID() (string, error) {
+	if i.mspID != "" {
+		return i.mspID, nil
+	}
+	return DefaultPlatformMSPID, nil
+}
+
+func (i *testIdentity) GetAttributeValue(attrName string) (string, bool, error) {
+	value, found := i.attrs[attrName]
+	return value, found, nil
+}
+
+func (i *testIdentity) AssertAttributeValue(attrName, attrValue string) error {
+	if value, found := i.attrs[attrName]; !found || value != attrValue {
+		return fmt.Errorf("attribute %s is not %s", attrName, attrValue)
+	}
+	return nil
+}
+
+func (i *testIdentity) GetX509Certificate() (*x509.Certificate, error) {
+	return nil, nil
+}
+
+// newTestContext returns a transaction context on a mock stub with the seeded catalogue and the
+// default configuration, penalties are not enforced against the external endpoint in tests.
+func newTestContext(t *testing.T) (*contractapi.TransactionContext, *shimtest.MockStub) {
+	t.Helper()
+
+	stub := shimtest.NewMockStub("tourism", nil)
+	stub.MockTransactionStart("tx1")
+	ctx := &contractapi.TransactionContext{}
+	ctx.SetStub(stub)
+	ctx.SetClientIdentity(&testIdentity{id: "anonymous"})
+
+	_, err := (&AdminContract{}).seedCatalogue(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := defaultConfig()
+	config.PenaltyEnforcementEnabled = false
+	jConfig, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = stub.PutState(contractConfigKey, jConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ctx, stub
+}
+
+// setRole makes the client of the context have the given role, providers own the given service.
+func setRole(ctx *contractapi.TransactionContext, role, sid string) {
+	attrs := map[string]string{IdentityAttributeRole: role}
+	if sid != "" {
+		attrs[IdentityAttributeServiceID] = sid
+	}
+	ctx.SetClientIdentity(&testIdentity{id: role + sid, attrs: attrs})
+}
+
+// putTestService stores the service in the world state.
+func putTestService(t *testing.T, stub *shimtest.MockStub, service *Service) {
+	t.Helper()
+
+	jService, err := json.Marshal(service)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = stub.PutState(service.ServiceID, jService)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// setTxTime sets the timestamp of the current transaction of the mock stub.
+func setTxTime(stub *shimtest.MockStub, at time.Time) {
+	stub.TxTimestamp.Seconds = at.Unix()
+	stub.TxTimestamp.Nanos = int32(at.Nanosecond())
+}
+
+// chaincodeEvents returns the payloads of the events set on the mock stub since the last call by event name.
+func chaincodeEvents(stub *shimtest.MockStub) map[string][][]byte {
+	events := make(map[string][][]byte)
+	for {
+		select {
+		case event := <-stub.ChaincodeEventsChannel:
+			events[event.EventName] = append(events[event.EventName], event.Payload)
+		default:
+			return events
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package smartcontract
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// validateHousekeepingItem validates the deadline of a housekeeping item.
+func validateHousekeepingItem(item *AgreementItem) error {
+	if item.HousekeepingDeadline != "" {
+		if _, err := parseClock(item.HousekeepingDeadline); err != nil {
+			return fmt.Errorf("invalid housekeeping deadline of agreement item %s: %v", item.Code, err)
+		}
+	}
+	if item.MaxMissedDays < 0 {
+		return fmt.Errorf("max missed days of agreement item %s must not be negative", item.Code)
+	}
+
+	return nil
+}
+
+// VerifyHousekeepingAgreement verify daily housekeeping agreement. A day of the stay is missed when
+// housekeeping was not completed on that day, or completed after the deadline when the agreement
+// has one. Completions outside the stay are ignored.
+// The agreement is unsatisfied when more days than MaxMissedDays are missed.
+func (s *EvaluationsContract) VerifyHousekeepingAgreement(_ contractapi.TransactionContextInterface, a *Agreement, data *EvaluationData) (*EvaluationResult, error) {
+	p, ok := payloadOf(AgreementCategoryService, data).(*housekeepingPayload)
+	if !ok {
+		return nil, fmt.Errorf("evaluation data code %s is not housekeeping service item code", data.Code)
+	}
+
+	return verifyHousekeepingAgreement(a, p)
+}
+
+// verifyHousekeepingAgreement verifies housekeeping agreement against the housekeeping payload.
+func verifyHousekeepingAgreement(a *Agreement, data *housekeepingPayload) (*EvaluationResult, error) {
+	st, err := newStay(data.StayStartDate, data.StayDays)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stay of housekeeping: %v", err)
+	}
+
+	agreementItem := a.Items[0]
+	deadline := -1
+	if agreementItem.HousekeepingDeadline != "" {
+		deadline, err = parseClock(agreementItem.HousekeepingDeadline)
+		if err != nil {
+			return nil, fmt.Errorf("invalid housekeeping deadline of agreement item %s: %v", agreementItem.Code, err)
+		}
+	}
+
+	// housekeeping is counted once a day
+	completedDays := make(map[string]bool)
+	for _, completedAt := range data.HousekeepingCompletedAt {
+		date := completedAt.Format(DateLayout)
+		if !st.has(date) || deadline >= 0 && clockOf(completedAt) > deadline {
+			continue
+		}
+		completedDays[date] = true
+	}
+
+	missedDays := data.StayDays - len(completedDays)
+
+	score := ratioScore(float64(data.StayDays-missedDays), float64(data.StayDays))
+	if missedDays <= agreementItem.MaxMissedDays {
+		return &EvaluationResult{
+			Satisfied: true,
+			Failures:  missedDays,
+			Score:     score,
+		}, nil
+	}
+
+	return &EvaluationResult{
+		Satisfied:     false,
+		PenaltyRules:  defaultPenaltyRules(a, 0),
+		FailureReason: fmt.Sprintf("housekeeping was missed on %d of %d days", missedDays, data.StayDays),
+		Failures:      missedDays,
+		Score:         score,
+	}, nil
+}
@@ -0,0 +1,126 @@
+package smartcontract
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerifyHousekeepingAgreement(t *testing.T) {
+	agreement := func(deadline string) *Agreement {
+		return &Agreement{
+			Category: AgreementCategoryService,
+			Items: []*AgreementItem{{
+				Code:                 AgreementItemCodeServiceHousekeeping,
+				HousekeepingDeadline: deadline,
+				MaxMissedDays:        1,
+			}},
+			HasPenaltyRule: true,
+			PenaltyRules:   []*PenaltyRule{{Type: PenaltyRuleTypeDiscount, DiscountPercent: 10}},
+		}
+	}
+	at := func(s string) time.Time {
+		t, err := time.Parse(DateLayout+" 15:04", s)
+		if err != nil {
+			panic(err)
+		}
+		return t
+	}
+
+	tests := []struct {
+		name      string
+		deadline  string
+		stayStart string
+		stayDays  int
+		completed []time.Time
+		wantErr   bool
+		satisfied bool
+		failures  int
+		score     float32
+	}{
+		{
+			name:      "completed every day",
+			stayStart: "2021-05-01",
+			stayDays:  2,
+			completed: []time.Time{at("2021-05-01 11:00"), at("2021-05-02 11:00")},
+			satisfied: true,
+			score:     1,
+		},
+		{
+			name:      "twice on a day and missed the next",
+			stayStart: "2021-05-01",
+			stayDays:  3,
+			completed: []time.Time{at("2021-05-01 09:00"), at("2021-05-01 15:00"), at("2021-05-03 11:00")},
+			satisfied: true,
+			failures:  1,
+			score:     float32(2) / 3,
+		},
+		{
+			name:      "completions outside the stay are ignored",
+			stayStart: "2021-05-01",
+			stayDays:  2,
+			completed: []time.Time{at("2021-04-30 11:00"), at("2021-05-03 11:00"), at("2021-05-04 11:00")},
+			failures:  2,
+			score:     0,
+		},
+		{
+			name:      "completed after the deadline",
+			deadline:  "14:00",
+			stayStart: "2021-05-01",
+			stayDays:  2,
+			completed: []time.Time{at("2021-05-01 14:01"), at("2021-05-02 15:00")},
+			failures:  2,
+			score:     0,
+		},
+		{
+			name:      "completed before the deadline",
+			deadline:  "14:00",
+			stayStart: "2021-05-01",
+			stayDays:  2,
+			completed: []time.Time{at("2021-05-01 14:00"), at("2021-05-02 13:00")},
+			satisfied: true,
+			score:     1,
+		},
+		{
+			name:      "missing stay days",
+			stayStart: "2021-05-01",
+			completed: []time.Time{at("2021-05-01 11:00")},
+			wantErr:   true,
+		},
+		{
+			name:      "invalid stay start date",
+			stayStart: "01/05/2021",
+			stayDays:  1,
+			completed: []time.Time{at("2021-05-01 11:00")},
+			wantErr:   true,
+		},
+	}
+
+	s := &EvaluationsContract{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := &EvaluationData{
+				Code:                    AgreementItemCodeServiceHousekeeping,
+				StayStartDate:           tt.stayStart,
+				StayDays:                tt.stayDays,
+				HousekeepingCompletedAt: tt.completed,
+			}
+			eResult, err := s.VerifyHousekeepingAgreement(nil, agreement(tt.deadline), data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if eResult.Satisfied != tt.satisfied {
+				t.Errorf("satisfied = %v, want %v", eResult.Satisfied, tt.satisfied)
+			}
+			if eResult.Failures != tt.failures {
+				t.Errorf("failures = %d, want %d", eResult.Failures, tt.failures)
+			}
+			if !floatEqual(eResult.Score, tt.score) {
+				t.Errorf("score = %v, want %v", eResult.Score, tt.score)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package smartcontract
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Ping checks the contract is reachable.
+func (s *AdminContract) Ping(_ contractapi.TransactionContextInterface) (string, error) {
+	return "pong", nil
+}
+
+// GetContractInfo returns the version, the supported agreement categories and the hash of
+// the configuration of the contract.
+func (s *AdminContract) GetContractInfo(ctx contractapi.TransactionContextInterface) (*ContractInfo, error) {
+	c, err := loadCatalogue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make([]string, 0, len(c.categories))
+	for category := range c.categories {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := configHash(config, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ContractInfo{
+		Version:             ContractVersion,
+		SupportedCategories: categories,
+		ConfigHash:          hash,
+	}, nil
+}
+
+// configHash returns the hex encoded SHA-256 hash of the configuration and the catalogue in effect,
+// peers running the same configuration return the same hash.
+func configHash(config *ContractConfig, c *catalogue) (string, error) {
+	jConfig, err := json.Marshal(map[string]interface{}{
+		"config":     config,
+		"categories": c.categories,
+		"items":      c.items,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal configuration: %v", err)
+	}
+
+	sum := sha256.Sum256(jConfig)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,307 @@
+package smartcontract
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Ledger keys
+const (
+	schemaVersionKey = "schema_version"
+)
+
+// Ledger events
+const (
+	EventSchemaMigrated = "SchemaMigrated"
+)
+
+// migration upgrades the stored JSON of a service to a schema version.
+type migration struct {
+	version int
+	migrate func(service map[string]interface{}) error
+}
+
+// migrations upgrade services from the previous schema version, in order of versions.
+var migrations = []*migration{
+	{version: 2, migrate: backfillAgreementScores},
+	{version: 3, migrate: versionAgreements},
+}
+
+// backfillAgreementScores scores evaluations recorded before agreements kept scores,
+// a satisfied evaluation scores 1 and an unsatisfied one scores 0.
+func backfillAgreementScores(service map[string]interface{}) error {
+	agreements, _ := service["agreements"].([]interface{})
+	for _, a := range agreements {
+		agreement, ok := a.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("agreement of service %v is not an object", service["serviceId"])
+		}
+
+		scoredFeedbacks, _ := agreement["scoredFeedbacks"].(float64)
+		totalFeedbacks, _ := agreement["totalFeedbacks"].(float64)
+		totalUnsatisfied, _ := agreement["totalUnsatisfied"].(float64)
+		if scoredFeedbacks > 0 || totalFeedbacks == 0 {
+			continue
+		}
+
+		totalScore := totalFeedbacks - totalUnsatisfied
+		agreement["totalScore"] = totalScore
+		agreement["scoredFeedbacks"] = totalFeedbacks
+		agreement["averageScore"] = totalScore / totalFeedbacks
+	}
+
+	return nil
+}
+
+// versionAgreements sets the first version to agreements stored before agreements were versioned.
+func versionAgreements(service map[string]interface{}) error {
+	agreements, _ := service["agreements"].([]interface{})
+	for _, a := range agreements {
+		agreement, ok := a.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("agreement of service %v is not an object", service["serviceId"])
+		}
+
+		if version, _ := agreement["version"].(float64); version == 0 {
+			agreement["version"] = 1
+		}
+	}
+
+	return nil
+}
+
+// InitLedger initializes the ledger with the configuration, the catalogue and the schema version built in
+// the chaincode. Services stored before the ledger is initialized keep the first schema version until they
+// are migrated. Only an admin of the platform org can initialize the ledger, it is invoked as admin:InitLedger.
+func (s *AdminContract) InitLedger(ctx contractapi.TransactionContextInterface) (*ContractConfig, error) {
+	// the ledger has no configuration yet, the platform org is the one built in the chaincode
+	config := defaultConfig()
+	err := assertPlatformRole(ctx, config, RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	jConfig, err := ctx.GetStub().GetState(contractConfigKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration from world state: %v", err)
+	}
+	if jConfig != nil {
+		return nil, fmt.Errorf("the ledger has already been initialized")
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = putConfig(ctx, config, 1, now.Format(RFC3339))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.seedCatalogue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := readSchemaVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hasServices, err := hasStoredServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !hasServices {
+		version = SchemaVersion
+	}
+	err = putSchemaVersion(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// GetSchemaVersion returns the schema version of the stored services.
+func (s *AdminContract) GetSchemaVersion(ctx contractapi.TransactionContextInterface) (int, error) {
+	return readSchemaVersion(ctx)
+}
+
+// Migrate upgrades a page of at most pageSize stored services, from the service with the start key on,
+// to the target schema version by applying the migrations of the versions in between. The next start key
+// of the returned migration continues with the next page, the schema version of the ledger is only raised
+// once the last page has been migrated. Only an admin of the platform org can migrate the ledger.
+func (s *AdminContract) Migrate(ctx contractapi.TransactionContextInterface, targetVersion int, startKey string, pageSize int) (*SchemaMigration, error) {
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = assertPlatformRole(ctx, config, RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("page size must be positive")
+	}
+
+	current, err := readSchemaVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if targetVersion <= current {
+		return nil, fmt.Errorf("the ledger is already at schema version %d", current)
+	}
+	if targetVersion > SchemaVersion {
+		return nil, fmt.Errorf("schema version %d has not supported yet, the latest is %d", targetVersion, SchemaVersion)
+	}
+
+	// range queries with pagination are not supported in transactions which write and the service
+	// index can not be ranged from a start key, as composite keys are only queried by prefix. Services
+	// are stored at their id, so the page ranges over the documents from the start key and skips the
+	// documents which are not services. Keys are valid UTF-8, so the largest rune ends the range.
+	resultsIterator, err := ctx.GetStub().GetStateByRange(startKey, string(utf8.MaxRune))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	schemaMigration := &SchemaMigration{
+		FromVersion: current,
+		ToVersion:   targetVersion,
+		TxID:        ctx.GetStub().GetTxID(),
+	}
+	visited := 0
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var doc struct {
+			DocType string `json:"docType"`
+		}
+		if json.Unmarshal(queryResponse.Value, &doc) != nil || doc.DocType != "Service" {
+			continue
+		}
+		if visited == pageSize {
+			schemaMigration.NextStartKey = queryResponse.Key
+			break
+		}
+		visited++
+
+		migrated, err := migrateService(ctx, queryResponse.Key, targetVersion)
+		if err != nil {
+			return nil, err
+		}
+		if migrated {
+			schemaMigration.MigratedServices++
+		}
+	}
+
+	if schemaMigration.NextStartKey == "" {
+		err = putSchemaVersion(ctx, targetVersion)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	jSchemaMigration, err := json.Marshal(schemaMigration)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().SetEvent(EventSchemaMigrated, jSchemaMigration)
+	if err != nil {
+		return nil, err
+	}
+
+	return schemaMigration, nil
+}
+
+// migrateService applies the migrations of the service up to the target schema version,
+// it returns false when the service is already at the target version.
+func migrateService(ctx contractapi.TransactionContextInterface, sid string, targetVersion int) (bool, error) {
+	jService, err := ctx.GetStub().GetState(sid)
+	if err != nil {
+		return false, err
+	}
+	if jService == nil {
+		return false, fmt.Errorf("the service %s does not exist", sid)
+	}
+
+	var service map[string]interface{}
+	err = json.Unmarshal(jService, &service)
+	if err != nil {
+		return false, err
+	}
+
+	// services stored before schema versions were recorded are at the first version
+	version := 1
+	if v, ok := service["schemaVersion"].(float64); ok && v > 0 {
+		version = int(v)
+	}
+	if version >= targetVersion {
+		return false, nil
+	}
+
+	for _, m := range migrations {
+		if m.version <= version || m.version > targetVersion {
+			continue
+		}
+		err = m.migrate(service)
+		if err != nil {
+			return false, fmt.Errorf("can not migrate service %s to schema version %d: %v", sid, m.version, err)
+		}
+	}
+	service["schemaVersion"] = targetVersion
+
+	jService, err = json.Marshal(service)
+	if err != nil {
+		return false, err
+	}
+	err = ctx.GetStub().PutState(sid, jService)
+	if err != nil {
+		return false, fmt.Errorf("fail to migrate service %s", sid)
+	}
+
+	return true, nil
+}
+
+// readSchemaVersion returns the schema version of the stored services, ledgers without
+// a schema version are at the first version.
+func readSchemaVersion(ctx contractapi.TransactionContextInterface) (int, error) {
+	jVersion, err := ctx.GetStub().GetState(schemaVersionKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema version from world state: %v", err)
+	}
+	if jVersion == nil {
+		return 1, nil
+	}
+
+	return strconv.Atoi(string(jVersion))
+}
+
+// putSchemaVersion writes the schema version of the stored services to the world state.
+func putSchemaVersion(ctx contractapi.TransactionContextInterface, version int) error {
+	err := ctx.GetStub().PutState(schemaVersionKey, []byte(strconv.Itoa(version)))
+	if err != nil {
+		return fmt.Errorf("fail to save schema version %d", version)
+	}
+
+	return nil
+}
+
+// hasStoredServices returns true when any service is stored in the world state.
+func hasStoredServices(ctx contractapi.TransactionContextInterface) (bool, error) {
+	serviceResultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(serviceIndex, []string{"Service"})
+	if err != nil {
+		return false, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	defer serviceResultsIterator.Close()
+
+	return serviceResultsIterator.HasNext(), nil
+}
@@ -0,0 +1,183 @@
+package smartcontract
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+)
+
+// putLegacyService stores a service at the first schema version, before agreements kept scores and versions.
+func putLegacyService(t *testing.T, stub *shimtest.MockStub, sid string) {
+	t.Helper()
+
+	jService := fmt.Sprintf(`{"docType":"Service","serviceId":%q,"agreements":[{"agreementId":"agreement1","category":"view","totalFeedbacks":4,"totalUnsatisfied":1}]}`, sid)
+	err := stub.PutState(sid, []byte(jService))
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexKey, err := stub.CreateCompositeKey(serviceIndex, []string{"Service", sid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = stub.PutState(indexKey, []byte{0x00})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name          string
+		role          string
+		clientMSPID   string
+		schemaVersion int
+		targetVersion int
+		pageSize      int
+		wantErr       bool
+	}{
+		{name: "latest version", role: RoleAdmin, schemaVersion: 1, targetVersion: SchemaVersion, pageSize: 10},
+		{name: "provider", role: RoleProvider, schemaVersion: 1, targetVersion: SchemaVersion, pageSize: 10, wantErr: true},
+		{name: "admin of another org", role: RoleAdmin, clientMSPID: "Org2MSP", schemaVersion: 1, targetVersion: SchemaVersion, pageSize: 10, wantErr: true},
+		{name: "zero page size", role: RoleAdmin, schemaVersion: 1, targetVersion: SchemaVersion, pageSize: 0, wantErr: true},
+		{name: "already migrated", role: RoleAdmin, schemaVersion: SchemaVersion, targetVersion: SchemaVersion, pageSize: 10, wantErr: true},
+		{name: "unknown version", role: RoleAdmin, schemaVersion: 1, targetVersion: SchemaVersion + 1, pageSize: 10, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, stub := newTestContext(t)
+			err := putSchemaVersion(ctx, tt.schemaVersion)
+			if err != nil {
+				t.Fatal(err)
+			}
+			putLegacyService(t, stub, "service1")
+			ctx.SetClientIdentity(&testIdentity{id: tt.role, mspID: tt.clientMSPID, attrs: map[string]string{IdentityAttributeRole: tt.role}})
+
+			schemaMigration, err := (&AdminContract{}).Migrate(ctx, tt.targetVersion, "", tt.pageSize)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if schemaMigration.MigratedServices != 1 || schemaMigration.NextStartKey != "" {
+				t.Errorf("migration = %+v, want 1 migrated service and no next page", schemaMigration)
+			}
+			service, err := readService(ctx, "service1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			a := service.Agreements[0]
+			if service.SchemaVersion != tt.targetVersion || a.Version != 1 || a.ScoredFeedbacks != 4 || !floatEqual(a.AverageScore, 0.75) {
+				t.Errorf("service = %+v, agreement = %+v, want migrated to schema version %d", service, a, tt.targetVersion)
+			}
+		})
+	}
+}
+
+func TestMigratePages(t *testing.T) {
+	ctx, stub := newTestContext(t)
+	setRole(ctx, RoleAdmin, "")
+	for _, sid := range []string{"service1", "service2", "service3"} {
+		putLegacyService(t, stub, sid)
+	}
+	// documents which are not services are stored between the services
+	err := stub.PutState("service2-e1", []byte(`{"docType":"Evaluation","evaluationId":"service2-e1"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &AdminContract{}
+
+	pages := []struct {
+		startKey          string
+		pageSize          int
+		wantMigrated      int
+		wantNextStartKey  string
+		wantSchemaVersion int
+	}{
+		{startKey: "", pageSize: 2, wantMigrated: 2, wantNextStartKey: "service3", wantSchemaVersion: 1},
+		// a page is migrated again when a earlier transaction was not committed
+		{startKey: "service2", pageSize: 1, wantMigrated: 0, wantNextStartKey: "service3", wantSchemaVersion: 1},
+		{startKey: "service3", pageSize: 2, wantMigrated: 1, wantSchemaVersion: SchemaVersion},
+	}
+	for i, page := range pages {
+		schemaMigration, err := s.Migrate(ctx, SchemaVersion, page.startKey, page.pageSize)
+		if err != nil {
+			t.Fatalf("page %d: %v", i, err)
+		}
+		if schemaMigration.MigratedServices != page.wantMigrated {
+			t.Errorf("page %d: migrated services = %d, want %d", i, schemaMigration.MigratedServices, page.wantMigrated)
+		}
+		if schemaMigration.NextStartKey != page.wantNextStartKey {
+			t.Errorf("page %d: next start key = %s, want %s", i, schemaMigration.NextStartKey, page.wantNextStartKey)
+		}
+		version, err := readSchemaVersion(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if version != page.wantSchemaVersion {
+			t.Errorf("page %d: schema version = %d, want %d", i, version, page.wantSchemaVersion)
+		}
+	}
+
+	for _, sid := range []string{"service1", "service2", "service3"} {
+		jService, err := stub.GetState(sid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var service Service
+		err = json.Unmarshal(jService, &service)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if service.SchemaVersion != SchemaVersion {
+			t.Errorf("schema version of %s = %d, want %d", sid, service.SchemaVersion, SchemaVersion)
+		}
+	}
+}
+
+func TestInitLedgerSchemaVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		hasServices bool
+		wantVersion int
+	}{
+		{name: "empty ledger", wantVersion: SchemaVersion},
+		{name: "ledger with services", hasServices: true, wantVersion: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, stub := newTestContext(t)
+			// the ledger is not initialized
+			err := stub.DelState(contractConfigKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.hasServices {
+				putLegacyService(t, stub, "service1")
+			}
+			setRole(ctx, RoleAdmin, "")
+
+			_, err = (&AdminContract{}).InitLedger(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			version, err := readSchemaVersion(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if version != tt.wantVersion {
+				t.Errorf("schema version = %d, want %d", version, tt.wantVersion)
+			}
+
+			_, err = (&AdminContract{}).InitLedger(ctx)
+			if err == nil {
+				t.Errorf("the ledger is initialized twice")
+			}
+		})
+	}
+}
@@ -0,0 +1,436 @@
+package smartcontract
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// evaluationPayload is the evaluation data of a agreement item decoded by it's item code, verifiers
+// take the payload type of the items they verify. Fields of a payload without omitempty are required.
+type evaluationPayload interface {
+	itemCode() string
+}
+
+// payloadHeader holds the fields every payload has.
+type payloadHeader struct {
+	Code string `json:"code"`
+	Name string `json:"name,omitempty"`
+}
+
+func (h payloadHeader) itemCode() string {
+	return h.Code
+}
+
+// facilityPayload is the evaluation data of interior, outdoor, bed and other facilities.
+type facilityPayload struct {
+	payloadHeader
+	Quantity int `json:"quantity,omitempty"`
+}
+
+// viewPayload is the evaluation data of a view.
+type viewPayload struct {
+	payloadHeader
+	Partial bool `json:"partial,omitempty"`
+}
+
+// airportShuttlePayload is the evaluation data of an airport shuttle.
+type airportShuttlePayload struct {
+	payloadHeader
+	Status                             string    `json:"status"`
+	PickUpTime                         time.Time `json:"pickUpTime,omitempty"`
+	DriverArriveAt                     time.Time `json:"driverArriveAt,omitempty"`
+	CustomerCheckInAt                  time.Time `json:"customerCheckInAt,omitempty"`
+	LastUpdatedArrivalTimeByCustomerAt time.Time `json:"lastUpdatedArrivalTimeByCustomerAt,omitempty"`
+	DriverNotifyCustomerDoNotShowUpAt  time.Time `json:"driverNotifyCustomerDoNotShowUpAt,omitempty"`
+}
+
+// saunaPayload is the evaluation data of a sauna.
+type saunaPayload struct {
+	payloadHeader
+	SaunaRequests []*SaunaRequest `json:"saunaRequests"`
+}
+
+// breakfastPayload is the evaluation data of breakfast, days of the stay which are not reported are missed.
+type breakfastPayload struct {
+	payloadHeader
+	StayStartDate string          `json:"stayStartDate"`
+	StayDays      int             `json:"stayDays"`
+	BreakfastDays []*BreakfastDay `json:"breakfastDays,omitempty"`
+}
+
+// housekeepingPayload is the evaluation data of daily housekeeping.
+type housekeepingPayload struct {
+	payloadHeader
+	StayStartDate           string      `json:"stayStartDate"`
+	StayDays                int         `json:"stayDays"`
+	HousekeepingCompletedAt []time.Time `json:"housekeepingCompletedAt,omitempty"`
+}
+
+// wifiPayload is the evaluation data of Wi-Fi.
+type wifiPayload struct {
+	payloadHeader
+	BandwidthSamplesMbps []float64 `json:"bandwidthSamplesMbps,omitempty"`
+	UptimePercent        float64   `json:"uptimePercent,omitempty"`
+}
+
+// checkInPayload is the evaluation data of front-desk check-in.
+type checkInPayload struct {
+	payloadHeader
+	ArriveAtFrontDeskAt time.Time `json:"arriveAtFrontDeskAt"`
+	CheckInServedAt     time.Time `json:"checkInServedAt"`
+}
+
+// servicePayload is the evaluation data of services without their own payload.
+type servicePayload struct {
+	payloadHeader
+	Fulfilled string `json:"fulfilled"`
+}
+
+// roomDesignPayload is the evaluation data of a room design measurement.
+type roomDesignPayload struct {
+	payloadHeader
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit,omitempty"`
+}
+
+// customPayload is the evaluation data of a custom term.
+type customPayload struct {
+	payloadHeader
+	Quantity  int                    `json:"quantity,omitempty"`
+	Status    string                 `json:"status,omitempty"`
+	Fulfilled string                 `json:"fulfilled,omitempty"`
+	Value     interface{}            `json:"value,omitempty"`
+	Metrics   map[string]interface{} `json:"metrics,omitempty"`
+}
+
+// codePayloadTypes maps item codes of the service category to their payload types.
+var codePayloadTypes = map[string]func() evaluationPayload{
+	AgreementItemCodeServiceAirportShuttle: func() evaluationPayload { return &airportShuttlePayload{} },
+	AgreementItemCodeServiceSauna:          func() evaluationPayload { return &saunaPayload{} },
+	AgreementItemCodeServiceBreakfast:      func() evaluationPayload { return &breakfastPayload{} },
+	AgreementItemCodeServiceHousekeeping:   func() evaluationPayload { return &housekeepingPayload{} },
+	AgreementItemCodeServiceWifi:           func() evaluationPayload { return &wifiPayload{} },
+	AgreementItemCodeServiceCheckIn:        func() evaluationPayload { return &checkInPayload{} },
+}
+
+// categoryPayloadTypes maps categories to payload types of item codes without their own payload type,
+// other categories use the facility payload.
+var categoryPayloadTypes = map[string]func() evaluationPayload{
+	AgreementCategoryView:       func() evaluationPayload { return &viewPayload{} },
+	AgreementCategoryService:    func() evaluationPayload { return &servicePayload{} },
+	AgreementCategoryRoomDesign: func() evaluationPayload { return &roomDesignPayload{} },
+	AgreementCategoryCustom:     func() evaluationPayload { return &customPayload{} },
+}
+
+// newEvaluationPayload returns an empty payload of the item code in the agreement category.
+func newEvaluationPayload(category, code string) evaluationPayload {
+	if category == AgreementCategoryService {
+		if newPayload, ok := codePayloadTypes[code]; ok {
+			return newPayload()
+		}
+	}
+	if newPayload, ok := categoryPayloadTypes[category]; ok {
+		return newPayload()
+	}
+
+	return &facilityPayload{}
+}
+
+// decodeEvaluationData decodes evaluation data of a agreement in the given category. Each entry is
+// decoded into the payload type of it's item code, unknown fields and missing required fields are rejected.
+func decodeEvaluationData(category string, data []byte) ([]evaluationPayload, error) {
+	var entries []json.RawMessage
+	err := json.Unmarshal(data, &entries)
+	if err != nil {
+		return nil, fmt.Errorf("can not unmarshal evaluation data: %v", err)
+	}
+
+	payloads := make([]evaluationPayload, 0, len(entries))
+	for i, entry := range entries {
+		var header payloadHeader
+		err = json.Unmarshal(entry, &header)
+		if err != nil {
+			return nil, fmt.Errorf("can not unmarshal evaluation data %d: %v", i, err)
+		}
+		if header.Code == "" {
+			return nil, fmt.Errorf("evaluation data %d has no code", i)
+		}
+
+		payload := newEvaluationPayload(category, header.Code)
+		decoder := json.NewDecoder(bytes.NewReader(entry))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(payload)
+		if err != nil {
+			return nil, fmt.Errorf("invalid evaluation data of item %s: %v", header.Code, err)
+		}
+
+		err = checkRequiredFields(reflect.ValueOf(payload), "")
+		if err != nil {
+			return nil, fmt.Errorf("invalid evaluation data of item %s: %v", header.Code, err)
+		}
+
+		payloads = append(payloads, payload)
+	}
+
+	return payloads, nil
+}
+
+// payloadOf returns the payload of the item code in the agreement category with the fields of
+// evaluation data given to the verify transactions, fields of other payloads are ignored.
+func payloadOf(category string, data *EvaluationData) evaluationPayload {
+	header := payloadHeader{Code: data.Code, Name: data.Name}
+
+	switch p := newEvaluationPayload(category, data.Code).(type) {
+	case *viewPayload:
+		p.payloadHeader, p.Partial = header, data.Partial
+		return p
+	case *airportShuttlePayload:
+		p.payloadHeader = header
+		p.Status = data.Status
+		p.PickUpTime = data.PickUpTime
+		p.DriverArriveAt = data.DriverArriveAt
+		p.CustomerCheckInAt = data.CustomerCheckInAt
+		p.LastUpdatedArrivalTimeByCustomerAt = data.LastUpdatedArrivalTimeByCustomerAt
+		p.DriverNotifyCustomerDoNotShowUpAt = data.DriverNotifyCustomerDoNotShowUpAt
+		return p
+	case *saunaPayload:
+		p.payloadHeader, p.SaunaRequests = header, data.SaunaRequests
+		return p
+	case *breakfastPayload:
+		p.payloadHeader, p.StayStartDate, p.StayDays, p.BreakfastDays = header, data.StayStartDate, data.StayDays, data.BreakfastDays
+		return p
+	case *housekeepingPayload:
+		p.payloadHeader, p.StayStartDate, p.StayDays, p.HousekeepingCompletedAt = header, data.StayStartDate, data.StayDays, data.HousekeepingCompletedAt
+		return p
+	case *wifiPayload:
+		p.payloadHeader, p.BandwidthSamplesMbps, p.UptimePercent = header, data.BandwidthSamplesMbps, data.UptimePercent
+		return p
+	case *checkInPayload:
+		p.payloadHeader, p.ArriveAtFrontDeskAt, p.CheckInServedAt = header, data.ArriveAtFrontDeskAt, data.CheckInServedAt
+		return p
+	case *servicePayload:
+		p.payloadHeader, p.Fulfilled = header, data.Fulfilled
+		return p
+	case *roomDesignPayload:
+		p.payloadHeader, p.Unit = header, data.Unit
+		p.Value, _ = data.Value.(float64)
+		return p
+	case *customPayload:
+		p.payloadHeader = header
+		p.Quantity, p.Status, p.Fulfilled, p.Value, p.Metrics = data.Quantity, data.Status, data.Fulfilled, data.Value, data.Metrics
+		return p
+	default:
+		return &facilityPayload{payloadHeader: header, Quantity: data.Quantity}
+	}
+}
+
+// payloadsOf returns the payloads of evaluation data given to the verify transactions.
+func payloadsOf(category string, eData []*EvaluationData) []evaluationPayload {
+	payloads := make([]evaluationPayload, len(eData))
+	for i, data := range eData {
+		payloads[i] = payloadOf(category, data)
+	}
+	return payloads
+}
+
+// findPayload returns the first payload with the given item code.
+func findPayload(payloads []evaluationPayload, code string) evaluationPayload {
+	for _, payload := range payloads {
+		if payload.itemCode() == code {
+			return payload
+		}
+	}
+	return nil
+}
+
+// facilityPayloads returns the facility payloads of the evaluation data.
+func facilityPayloads(payloads []evaluationPayload) []*facilityPayload {
+	var facilities []*facilityPayload
+	for _, payload := range payloads {
+		if p, ok := payload.(*facilityPayload); ok {
+			facilities = append(facilities, p)
+		}
+	}
+	return facilities
+}
+
+// viewPayloads returns the view payloads of the evaluation data.
+func viewPayloads(payloads []evaluationPayload) []*viewPayload {
+	var views []*viewPayload
+	for _, payload := range payloads {
+		if p, ok := payload.(*viewPayload); ok {
+			views = append(views, p)
+		}
+	}
+	return views
+}
+
+// customPayloads returns the custom term payloads of the evaluation data.
+func customPayloads(payloads []evaluationPayload) []*customPayload {
+	var terms []*customPayload
+	for _, payload := range payloads {
+		if p, ok := payload.(*customPayload); ok {
+			terms = append(terms, p)
+		}
+	}
+	return terms
+}
+
+// checkRequiredFields returns an error when a field without omitempty has the zero value.
+// Structs in pointers and slices are checked recursively.
+func checkRequiredFields(v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return checkRequiredFields(v.Elem(), path)
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			err := checkRequiredFields(v.Index(i), fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+		if v.Type() == reflect.TypeOf(time.Time{}) {
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			err := checkRequiredFields(v.Field(i), path)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		name, omitEmpty := jsonFieldName(field)
+		if name == "" {
+			continue
+		}
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+
+		if !omitEmpty && v.Field(i).IsZero() {
+			return fmt.Errorf("field %s is required", fieldPath)
+		}
+		err := checkRequiredFields(v.Field(i), fieldPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// jsonFieldName returns the JSON name of a struct field and whether it is omitted when empty.
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" || field.PkgPath != "" {
+		return "", false
+	}
+
+	parts := strings.Split(tag, ",")
+	name := parts[0]
+	if name == "" {
+		name = field.Name
+	}
+
+	return name, StringInSlice("omitempty", parts[1:])
+}
+
+// GetEvaluationDataSchema returns the JSON schema of the evaluation data of a item code in a agreement category,
+// clients can use it to validate evaluation data before submitting.
+func (s *EvaluationsContract) GetEvaluationDataSchema(_ contractapi.TransactionContextInterface, category, code string) (string, error) {
+	payload := newEvaluationPayload(category, code)
+
+	schema := jsonSchemaOf(reflect.TypeOf(payload))
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = fmt.Sprintf("Evaluation data of item %s", code)
+	if properties, ok := schema["properties"].(map[string]interface{}); ok && code != "" {
+		properties["code"] = map[string]interface{}{"const": code}
+	}
+
+	jSchema, err := json.Marshal(schema)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jSchema), nil
+}
+
+// jsonSchemaOf returns the JSON schema of a Go type.
+func jsonSchemaOf(t reflect.Type) map[string]interface{} {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": jsonSchemaOf(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": jsonSchemaOf(t.Elem())}
+	case reflect.Struct:
+		if t == reflect.TypeOf(time.Time{}) {
+			return map[string]interface{}{"type": "string", "format": "date-time"}
+		}
+	default:
+		return map[string]interface{}{}
+	}
+
+	properties := make(map[string]interface{})
+	required := []string{}
+	addStructProperties(t, properties, &required)
+
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+// addStructProperties adds the fields of a struct and it's embedded structs to the schema properties.
+func addStructProperties(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			addStructProperties(field.Type, properties, required)
+			continue
+		}
+
+		name, omitEmpty := jsonFieldName(field)
+		if name == "" {
+			continue
+		}
+		properties[name] = jsonSchemaOf(field.Type)
+		if !omitEmpty {
+			*required = append(*required, name)
+		}
+	}
+}
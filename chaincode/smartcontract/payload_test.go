@@ -0,0 +1,200 @@
+package smartcontract
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestDecodeEvaluationData(t *testing.T) {
+	tests := []struct {
+		name     string
+		category string
+		data     string
+		wantErr  bool
+		want     evaluationPayload
+	}{
+		{
+			name:     "facility",
+			category: AgreementCategoryInterior,
+			data:     `[{"code":"IBA001","quantity":2}]`,
+			want:     &facilityPayload{payloadHeader: payloadHeader{Code: AgreementItemCodeInteriorBathtub}, Quantity: 2},
+		},
+		{
+			name:     "view",
+			category: AgreementCategoryView,
+			data:     `[{"code":"V001","partial":true}]`,
+			want:     &viewPayload{payloadHeader: payloadHeader{Code: AgreementItemCodeViewSea}, Partial: true},
+		},
+		{
+			name:     "room design",
+			category: AgreementCategoryRoomDesign,
+			data:     `[{"code":"RSI001","value":30.5,"unit":"m2"}]`,
+			want:     &roomDesignPayload{payloadHeader: payloadHeader{Code: AgreementItemCodeRoomDesignSize}, Value: 30.5, Unit: UnitSquareMeter},
+		},
+		{
+			name:     "housekeeping",
+			category: AgreementCategoryService,
+			data:     `[{"code":"SHK001","stayStartDate":"2021-05-01","stayDays":2}]`,
+			want:     &housekeepingPayload{payloadHeader: payloadHeader{Code: AgreementItemCodeServiceHousekeeping}, StayStartDate: "2021-05-01", StayDays: 2},
+		},
+		{
+			name:     "wifi",
+			category: AgreementCategoryService,
+			data:     `[{"code":"SWI001","bandwidthSamplesMbps":[10,20],"uptimePercent":99}]`,
+			want:     &wifiPayload{payloadHeader: payloadHeader{Code: AgreementItemCodeServiceWifi}, BandwidthSamplesMbps: []float64{10, 20}, UptimePercent: 99},
+		},
+		{
+			name:     "service without its own payload",
+			category: AgreementCategoryService,
+			data:     `[{"code":"SXX001","fulfilled":"yes"}]`,
+			want:     &servicePayload{payloadHeader: payloadHeader{Code: "SXX001"}, Fulfilled: "yes"},
+		},
+		{
+			name:     "custom term with a service code",
+			category: AgreementCategoryCustom,
+			data:     `[{"code":"SWI001","metrics":{"latency":20}}]`,
+			want:     &customPayload{payloadHeader: payloadHeader{Code: AgreementItemCodeServiceWifi}, Metrics: map[string]interface{}{"latency": float64(20)}},
+		},
+		{
+			name:     "service code in another category",
+			category: AgreementCategoryBed,
+			data:     `[{"code":"SWI001","quantity":1}]`,
+			want:     &facilityPayload{payloadHeader: payloadHeader{Code: AgreementItemCodeServiceWifi}, Quantity: 1},
+		},
+		{
+			name:     "field of another payload",
+			category: AgreementCategoryService,
+			data:     `[{"code":"SWI001","quantity":1}]`,
+			wantErr:  true,
+		},
+		{
+			name:     "missing required field",
+			category: AgreementCategoryService,
+			data:     `[{"code":"SCI001","arriveAtFrontDeskAt":"2021-05-01T14:00:00Z"}]`,
+			wantErr:  true,
+		},
+		{
+			name:     "missing required field of a nested payload",
+			category: AgreementCategoryService,
+			data:     `[{"code":"SSA001","saunaRequests":[{"requestAt":"2021-05-01T14:00:00Z"}]}]`,
+			wantErr:  true,
+		},
+		{
+			name:     "wrong field type",
+			category: AgreementCategoryRoomDesign,
+			data:     `[{"code":"RSI001","value":"30"}]`,
+			wantErr:  true,
+		},
+		{
+			name:     "missing code",
+			category: AgreementCategoryInterior,
+			data:     `[{"quantity":1}]`,
+			wantErr:  true,
+		},
+		{
+			name:     "not an array",
+			category: AgreementCategoryInterior,
+			data:     `{"code":"IBA001"}`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payloads, err := decodeEvaluationData(tt.category, []byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(payloads) != 1 || !reflect.DeepEqual(payloads[0], tt.want) {
+				t.Errorf("payload = %+v, want %+v", payloads[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestPayloadOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		category string
+		data     *EvaluationData
+		want     evaluationPayload
+	}{
+		{
+			name:     "sauna",
+			category: AgreementCategoryService,
+			data:     &EvaluationData{Code: AgreementItemCodeServiceSauna, Quantity: 1, SaunaRequests: []*SaunaRequest{{Status: "success"}}},
+			want:     &saunaPayload{payloadHeader: payloadHeader{Code: AgreementItemCodeServiceSauna}, SaunaRequests: []*SaunaRequest{{Status: "success"}}},
+		},
+		{
+			name:     "room design",
+			category: AgreementCategoryRoomDesign,
+			data:     &EvaluationData{Code: AgreementItemCodeRoomDesignSize, Value: 30.5, Unit: UnitSquareMeter},
+			want:     &roomDesignPayload{payloadHeader: payloadHeader{Code: AgreementItemCodeRoomDesignSize}, Value: 30.5, Unit: UnitSquareMeter},
+		},
+		{
+			name:     "facility",
+			category: AgreementCategoryBed,
+			data:     &EvaluationData{Code: AgreementItemCodeBedQueen, Name: "queen", Quantity: 2, Partial: true},
+			want:     &facilityPayload{payloadHeader: payloadHeader{Code: AgreementItemCodeBedQueen, Name: "queen"}, Quantity: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := payloadOf(tt.category, tt.data)
+			if !reflect.DeepEqual(payload, tt.want) {
+				t.Errorf("payload = %+v, want %+v", payload, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetEvaluationDataSchema(t *testing.T) {
+	tests := []struct {
+		category string
+		code     string
+		required []string
+	}{
+		{category: AgreementCategoryService, code: AgreementItemCodeServiceCheckIn, required: []string{"arriveAtFrontDeskAt", "checkInServedAt", "code"}},
+		{category: AgreementCategoryService, code: AgreementItemCodeServiceBreakfast, required: []string{"code", "stayDays", "stayStartDate"}},
+		{category: AgreementCategoryRoomDesign, code: AgreementItemCodeRoomDesignSize, required: []string{"code", "value"}},
+		{category: AgreementCategoryInterior, code: AgreementItemCodeInteriorBathtub, required: []string{"code"}},
+	}
+
+	s := &EvaluationsContract{}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			jSchema, err := s.GetEvaluationDataSchema(nil, tt.category, tt.code)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var schema struct {
+				Properties           map[string]map[string]interface{} `json:"properties"`
+				Required             []string                          `json:"required"`
+				AdditionalProperties bool                              `json:"additionalProperties"`
+			}
+			err = json.Unmarshal([]byte(jSchema), &schema)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sort.Strings(schema.Required)
+			if !reflect.DeepEqual(schema.Required, tt.required) {
+				t.Errorf("required = %v, want %v", schema.Required, tt.required)
+			}
+			if schema.Properties["code"]["const"] != tt.code {
+				t.Errorf("code = %v, want const %s", schema.Properties["code"], tt.code)
+			}
+			if schema.AdditionalProperties {
+				t.Errorf("schema allows additional properties")
+			}
+		})
+	}
+}
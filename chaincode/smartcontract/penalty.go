@@ -0,0 +1,252 @@
+package smartcontract
+
+import (
+	"fmt"
+	"sort"
+)
+
+// validatePenaltyRules validates penalty rules of a agreement against it's items and the view ranking of the service.
+func validatePenaltyRules(c *catalogue, items []*AgreementItem, viewRanking []string, penaltyRules []*PenaltyRule) error {
+	for _, rule := range penaltyRules {
+		switch rule.Type {
+		case PenaltyRuleTypeDiscount:
+		case PenaltyRuleTypeUpgradeLevel:
+			if rule.UpgradeLevels <= 0 {
+				return fmt.Errorf("upgrade levels of penalty rule must be positive")
+			}
+			if _, err := computeUpgradeTarget(c, items, viewRanking, rule); err != nil {
+				return err
+			}
+		default:
+			// rules of other types were accepted before penalty rule types were validated, they are
+			// passed to the penalty endpoint as they are and only their amount is combined
+		}
+
+		if rule.Severity != "" && !StringInSlice(rule.Severity, []string{PenaltySeverityMinor, PenaltySeverityMajor, PenaltySeverityCritical}) {
+			return fmt.Errorf("penalty severity %s has not supported yet", rule.Severity)
+		}
+		if rule.MaxDelayMinutes > 0 && rule.MaxDelayMinutes <= rule.MinDelayMinutes {
+			return fmt.Errorf("max delay minutes of penalty rule must be greater than min delay minutes")
+		}
+	}
+
+	return nil
+}
+
+// validatePenaltyCombination validates the way penalty rules of a agreement are combined.
+func validatePenaltyCombination(combination string, maxDiscountPercent, maxAmount float32) error {
+	if combination != "" && combination != PenaltyCombinationFirst && combination != PenaltyCombinationCumulative {
+		return fmt.Errorf("penalty combination %s has not supported yet", combination)
+	}
+	if maxDiscountPercent < 0 || maxDiscountPercent > 100 {
+		return fmt.Errorf("max discount percent must be between 0 and 100")
+	}
+	if maxAmount < 0 {
+		return fmt.Errorf("max amount must not be negative")
+	}
+
+	return nil
+}
+
+// defaultPenaltyRules returns the penalty rule at the given position, or the last one when the agreement
+// has fewer rules. It is used for agreements whose penalty rules declare no trigger condition.
+func defaultPenaltyRules(a *Agreement, i int) []*PenaltyRule {
+	if !a.HasPenaltyRule || len(a.PenaltyRules) == 0 {
+		return nil
+	}
+	if i >= len(a.PenaltyRules) {
+		i = len(a.PenaltyRules) - 1
+	}
+
+	return []*PenaltyRule{a.PenaltyRules[i]}
+}
+
+// applyPenaltyRules selects the penalty rules triggered by an unsatisfied evaluation result,
+// combines them under the penalty combination of the agreement and caps the compensation.
+func applyPenaltyRules(c *catalogue, a *Agreement, viewRanking []string, eResult *EvaluationResult) error {
+	if eResult.Satisfied || !a.HasPenaltyRule {
+		eResult.PenaltyRules = nil
+		eResult.PenaltyRule = nil
+		return nil
+	}
+
+	if hasTriggerConditions(a.PenaltyRules) {
+		eResult.PenaltyRules = selectPenaltyRules(a, eResult)
+	}
+	eResult.PenaltyRule = firstPenaltyRule(eResult.PenaltyRules)
+
+	var upgradeRule *PenaltyRule
+	eResult.DiscountPercent = 0
+	eResult.Amount = 0
+	for _, rule := range eResult.PenaltyRules {
+		eResult.Amount += rule.Amount
+		switch rule.Type {
+		case PenaltyRuleTypeDiscount:
+			eResult.DiscountPercent += rule.DiscountPercent
+		case PenaltyRuleTypeUpgradeLevel:
+			if upgradeRule == nil || rule.UpgradeLevels > upgradeRule.UpgradeLevels {
+				upgradeRule = rule
+			}
+		}
+	}
+
+	if eResult.DiscountPercent > 100 {
+		eResult.DiscountPercent = 100
+	}
+	if a.MaxDiscountPercent > 0 && eResult.DiscountPercent > a.MaxDiscountPercent {
+		eResult.DiscountPercent = a.MaxDiscountPercent
+	}
+	if a.MaxAmount > 0 && eResult.Amount > a.MaxAmount {
+		eResult.Amount = a.MaxAmount
+	}
+
+	eResult.UpgradeTarget = ""
+	if upgradeRule != nil {
+		upgradeTarget, err := computeUpgradeTarget(c, a.Items, viewRanking, upgradeRule)
+		if err != nil {
+			return err
+		}
+		eResult.UpgradeTarget = upgradeTarget
+	}
+
+	return nil
+}
+
+// firstPenaltyRule returns the first of the penalty rules, or nil when there is none.
+func firstPenaltyRule(penaltyRules []*PenaltyRule) *PenaltyRule {
+	if len(penaltyRules) == 0 {
+		return nil
+	}
+	return penaltyRules[0]
+}
+
+// selectPenaltyRules returns penalty rules whose trigger condition matches the evaluation result.
+func selectPenaltyRules(a *Agreement, eResult *EvaluationResult) []*PenaltyRule {
+	var penaltyRules []*PenaltyRule
+	for _, rule := range a.PenaltyRules {
+		if !matchPenaltyRule(rule, eResult) {
+			continue
+		}
+
+		penaltyRules = append(penaltyRules, rule)
+		if a.PenaltyCombination != PenaltyCombinationCumulative {
+			break
+		}
+	}
+
+	return penaltyRules
+}
+
+// hasTriggerConditions returns true when any of the penalty rules declares a trigger condition.
+func hasTriggerConditions(penaltyRules []*PenaltyRule) bool {
+	for _, rule := range penaltyRules {
+		if rule.Severity != "" || rule.MinDelayMinutes > 0 || rule.MaxDelayMinutes > 0 ||
+			rule.MinFailures > 0 || rule.MinRepeatOffenses > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchPenaltyRule returns true when the trigger condition of the penalty rule matches the evaluation result.
+// The delay range includes the min delay and excludes the max delay.
+func matchPenaltyRule(rule *PenaltyRule, eResult *EvaluationResult) bool {
+	severity := eResult.Severity
+	if severity == "" {
+		severity = PenaltySeverityMajor
+	}
+
+	switch {
+	case rule.Severity != "" && rule.Severity != severity:
+		return false
+	case rule.MinDelayMinutes > 0 && eResult.DelayMinutes < rule.MinDelayMinutes:
+		return false
+	case rule.MaxDelayMinutes > 0 && eResult.DelayMinutes >= rule.MaxDelayMinutes:
+		return false
+	case rule.MinFailures > 0 && eResult.Failures < rule.MinFailures:
+		return false
+	case rule.MinRepeatOffenses > 0 && eResult.RepeatOffenses < rule.MinRepeatOffenses:
+		return false
+	}
+
+	return true
+}
+
+// computeUpgradeTarget computes the code, bed or room category a guest is upgraded to
+// by a upgrade_level penalty rule. Views are ranked by the view ranking of the service, or by
+// the catalogue when it is empty. The base is the highest ranked agreement item of the upgrade
+// dimension, every upgrade level is a higher level than the previous one, codes of the same level
+// are not an upgrade, and the target is capped at the highest level.
+func computeUpgradeTarget(c *catalogue, items []*AgreementItem, viewRanking []string, rule *PenaltyRule) (string, error) {
+	var levels map[string]int
+	var bases []string
+	switch rule.UpgradeDimension {
+	case UpgradeDimensionView:
+		levels = viewLevels(c, viewRanking)
+		for _, item := range items {
+			bases = append(bases, item.Code)
+		}
+	case UpgradeDimensionBed:
+		levels = c.ranks(AgreementCategoryBed)
+		for _, item := range items {
+			bases = append(bases, item.Code)
+		}
+	case UpgradeDimensionRoomCategory:
+		levels = RoomCategoryLevelMapping
+		for _, item := range items {
+			bases = append(bases, item.RoomCategory)
+		}
+	default:
+		return "", fmt.Errorf("upgrade dimension %s has not supported yet", rule.UpgradeDimension)
+	}
+
+	base := ""
+	for _, code := range bases {
+		if level, ok := levels[code]; ok && (base == "" || level > levels[base]) {
+			base = code
+		}
+	}
+	if base == "" {
+		return "", fmt.Errorf("agreement items have nothing to upgrade in dimension %s", rule.UpgradeDimension)
+	}
+
+	// the first code of each level above the base, from the lowest level
+	var targets []string
+	for _, code := range rankByLevel(levels) {
+		if levels[code] <= levels[base] {
+			continue
+		}
+		if len(targets) == 0 || levels[targets[len(targets)-1]] < levels[code] {
+			targets = append(targets, code)
+		}
+	}
+	if len(targets) == 0 {
+		// the base is already at the highest level
+		return base, nil
+	}
+
+	targetRank := rule.UpgradeLevels - 1
+	if targetRank >= len(targets) {
+		targetRank = len(targets) - 1
+	}
+
+	return targets[targetRank], nil
+}
+
+// rankByLevel returns codes of a level mapping ordered from the lowest to the highest level.
+func rankByLevel(levels map[string]int) []string {
+	ranking := make([]string, 0, len(levels))
+	for code := range levels {
+		ranking = append(ranking, code)
+	}
+
+	sort.Slice(ranking, func(i, j int) bool {
+		if levels[ranking[i]] == levels[ranking[j]] {
+			return ranking[i] < ranking[j]
+		}
+		return levels[ranking[i]] < levels[ranking[j]]
+	})
+
+	return ranking
+}
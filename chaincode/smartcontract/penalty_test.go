@@ -0,0 +1,326 @@
+package smartcontract
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestComputeUpgradeTarget(t *testing.T) {
+	c := defaultCatalogue()
+
+	tests := []struct {
+		name      string
+		items     []*AgreementItem
+		ranking   []string
+		dimension string
+		levels    int
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "view by one level",
+			items:     []*AgreementItem{{Code: AgreementItemCodeViewGarden}},
+			dimension: UpgradeDimensionView,
+			levels:    1,
+			want:      AgreementItemCodeViewPool,
+		},
+		{
+			name:      "view from the highest agreed view",
+			items:     []*AgreementItem{{Code: AgreementItemCodeViewCity}, {Code: AgreementItemCodeViewGarden}},
+			dimension: UpgradeDimensionView,
+			levels:    2,
+			want:      AgreementItemCodeViewRiver,
+		},
+		{
+			name:      "view capped at the highest level",
+			items:     []*AgreementItem{{Code: AgreementItemCodeViewRiver}},
+			dimension: UpgradeDimensionView,
+			levels:    3,
+			want:      AgreementItemCodeViewSea,
+		},
+		{
+			name:      "view by the ranking of the service",
+			items:     []*AgreementItem{{Code: AgreementItemCodeViewGarden}},
+			ranking:   []string{AgreementItemCodeViewPool, AgreementItemCodeViewGarden, AgreementItemCodeViewCity, AgreementItemCodeViewSea},
+			dimension: UpgradeDimensionView,
+			levels:    1,
+			want:      AgreementItemCodeViewCity,
+		},
+		{
+			name:      "view at the top of the ranking of the service",
+			items:     []*AgreementItem{{Code: AgreementItemCodeViewPool}},
+			ranking:   []string{AgreementItemCodeViewSea, AgreementItemCodeViewPool},
+			dimension: UpgradeDimensionView,
+			levels:    1,
+			want:      AgreementItemCodeViewPool,
+		},
+		{
+			name:      "view not in the ranking of the service",
+			items:     []*AgreementItem{{Code: AgreementItemCodeViewGarden}},
+			ranking:   []string{AgreementItemCodeViewCity, AgreementItemCodeViewSea},
+			dimension: UpgradeDimensionView,
+			levels:    1,
+			wantErr:   true,
+		},
+		{
+			name:      "bed by one level",
+			items:     []*AgreementItem{{Code: AgreementItemCodeBedTwin}},
+			dimension: UpgradeDimensionBed,
+			levels:    1,
+			want:      AgreementItemCodeBedDouble,
+		},
+		{
+			name:      "bed capped at the highest level",
+			items:     []*AgreementItem{{Code: AgreementItemCodeBedQueen}},
+			dimension: UpgradeDimensionBed,
+			levels:    5,
+			want:      AgreementItemCodeBedKing,
+		},
+		{
+			name:      "room category by one level",
+			items:     []*AgreementItem{{Code: AgreementItemCodeViewSea, RoomCategory: RoomCategoryDeluxe}},
+			dimension: UpgradeDimensionRoomCategory,
+			levels:    1,
+			want:      RoomCategoryJuniorSuite,
+		},
+		{
+			name:      "room category without booked category",
+			items:     []*AgreementItem{{Code: AgreementItemCodeViewSea}},
+			dimension: UpgradeDimensionRoomCategory,
+			levels:    1,
+			wantErr:   true,
+		},
+		{
+			name:      "view of bed items",
+			items:     []*AgreementItem{{Code: AgreementItemCodeBedTwin}},
+			dimension: UpgradeDimensionView,
+			levels:    1,
+			wantErr:   true,
+		},
+		{
+			name:      "unknown dimension",
+			items:     []*AgreementItem{{Code: AgreementItemCodeViewSea}},
+			dimension: "floor",
+			levels:    1,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := &PenaltyRule{Type: PenaltyRuleTypeUpgradeLevel, UpgradeDimension: tt.dimension, UpgradeLevels: tt.levels}
+			got, err := computeUpgradeTarget(c, tt.items, tt.ranking, rule)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("upgrade target = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeUpgradeTargetSkipsSameLevel(t *testing.T) {
+	// the pool view has the level of the garden view, it is not an upgrade of it
+	c := defaultCatalogue()
+	c.items[AgreementItemCodeViewPool].Rank = ViewLevelMapping[AgreementItemCodeViewGarden]
+	items := []*AgreementItem{{Code: AgreementItemCodeViewGarden}}
+
+	tests := []struct {
+		levels int
+		want   string
+	}{
+		{levels: 1, want: AgreementItemCodeViewRiver},
+		{levels: 2, want: AgreementItemCodeViewSea},
+	}
+
+	for _, tt := range tests {
+		rule := &PenaltyRule{Type: PenaltyRuleTypeUpgradeLevel, UpgradeDimension: UpgradeDimensionView, UpgradeLevels: tt.levels}
+		got, err := computeUpgradeTarget(c, items, nil, rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("upgrade target by %d levels = %s, want %s", tt.levels, got, tt.want)
+		}
+	}
+}
+
+func TestApplyPenaltyRules(t *testing.T) {
+	c := defaultCatalogue()
+	minor := &PenaltyRule{Type: PenaltyRuleTypeDiscount, DiscountPercent: 10, Severity: PenaltySeverityMinor}
+	late := &PenaltyRule{Type: PenaltyRuleTypeDiscount, DiscountPercent: 30, Severity: PenaltySeverityMajor, MinDelayMinutes: 15, MaxDelayMinutes: 60}
+	critical := &PenaltyRule{Type: PenaltyRuleTypeDiscount, DiscountPercent: 50, Severity: PenaltySeverityCritical}
+	failures := &PenaltyRule{Type: PenaltyRuleTypeDiscount, DiscountPercent: 20, MinFailures: 3}
+	repeated := &PenaltyRule{Type: PenaltyRuleTypeDiscount, DiscountPercent: 5, Amount: 100, MinRepeatOffenses: 2}
+	conditional := []*PenaltyRule{minor, late, critical, failures, repeated}
+	voucher := &PenaltyRule{Type: "voucher", Amount: 25}
+
+	tests := []struct {
+		name      string
+		agreement *Agreement
+		result    *EvaluationResult
+
+		want     []*PenaltyRule
+		discount float32
+		amount   float32
+	}{
+		{
+			name:      "satisfied",
+			agreement: &Agreement{HasPenaltyRule: true, PenaltyRules: conditional},
+			result:    &EvaluationResult{Satisfied: true, Severity: PenaltySeverityCritical},
+		},
+		{
+			name:      "agreement without penalty",
+			agreement: &Agreement{PenaltyRules: conditional},
+			result:    &EvaluationResult{Severity: PenaltySeverityCritical},
+		},
+		{
+			name:      "severity",
+			agreement: &Agreement{HasPenaltyRule: true, PenaltyRules: conditional},
+			result:    &EvaluationResult{Severity: PenaltySeverityMinor},
+			want:      []*PenaltyRule{minor},
+			discount:  10,
+		},
+		{
+			name:      "delay within range",
+			agreement: &Agreement{HasPenaltyRule: true, PenaltyRules: conditional},
+			result:    &EvaluationResult{Severity: PenaltySeverityMajor, DelayMinutes: 15},
+			want:      []*PenaltyRule{late},
+			discount:  30,
+		},
+		{
+			name:      "missing severity is major",
+			agreement: &Agreement{HasPenaltyRule: true, PenaltyRules: conditional},
+			result:    &EvaluationResult{DelayMinutes: 20},
+			want:      []*PenaltyRule{late},
+			discount:  30,
+		},
+		{
+			name:      "max delay is excluded",
+			agreement: &Agreement{HasPenaltyRule: true, PenaltyRules: conditional},
+			result:    &EvaluationResult{Severity: PenaltySeverityMajor, DelayMinutes: 60},
+		},
+		{
+			name:      "failures",
+			agreement: &Agreement{HasPenaltyRule: true, PenaltyRules: conditional},
+			result:    &EvaluationResult{Severity: PenaltySeverityMajor, Failures: 3},
+			want:      []*PenaltyRule{failures},
+			discount:  20,
+		},
+		{
+			name:      "first matching rule",
+			agreement: &Agreement{HasPenaltyRule: true, PenaltyRules: conditional},
+			result:    &EvaluationResult{Severity: PenaltySeverityCritical, RepeatOffenses: 2},
+			want:      []*PenaltyRule{critical},
+			discount:  50,
+		},
+		{
+			name:      "cumulative",
+			agreement: &Agreement{HasPenaltyRule: true, PenaltyRules: conditional, PenaltyCombination: PenaltyCombinationCumulative},
+			result:    &EvaluationResult{Severity: PenaltySeverityCritical, RepeatOffenses: 2},
+			want:      []*PenaltyRule{critical, repeated},
+			discount:  55,
+			amount:    100,
+		},
+		{
+			name: "cumulative capped",
+			agreement: &Agreement{HasPenaltyRule: true, PenaltyRules: conditional, PenaltyCombination: PenaltyCombinationCumulative,
+				MaxDiscountPercent: 40, MaxAmount: 80},
+			result:   &EvaluationResult{Severity: PenaltySeverityCritical, RepeatOffenses: 2},
+			want:     []*PenaltyRule{critical, repeated},
+			discount: 40,
+			amount:   80,
+		},
+		{
+			name:      "rule without trigger condition of a legacy type",
+			agreement: &Agreement{HasPenaltyRule: true, PenaltyRules: []*PenaltyRule{voucher}},
+			result:    &EvaluationResult{PenaltyRules: []*PenaltyRule{voucher}},
+			want:      []*PenaltyRule{voucher},
+			amount:    25,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := applyPenaltyRules(c, tt.agreement, nil, tt.result)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(tt.result.PenaltyRules) != len(tt.want) {
+				t.Fatalf("penalty rules = %v, want %v", tt.result.PenaltyRules, tt.want)
+			}
+			for i := range tt.want {
+				if tt.result.PenaltyRules[i] != tt.want[i] {
+					t.Errorf("penalty rule %d = %v, want %v", i, tt.result.PenaltyRules[i], tt.want[i])
+				}
+			}
+			if tt.result.PenaltyRule != firstPenaltyRule(tt.want) {
+				t.Errorf("first penalty rule = %v, want %v", tt.result.PenaltyRule, firstPenaltyRule(tt.want))
+			}
+			if tt.result.DiscountPercent != tt.discount || tt.result.Amount != tt.amount {
+				t.Errorf("discount = %v, amount = %v, want %v, %v", tt.result.DiscountPercent, tt.result.Amount, tt.discount, tt.amount)
+			}
+		})
+	}
+}
+
+func TestEvaluationResultKeepsPenaltyRule(t *testing.T) {
+	rule := &PenaltyRule{Type: PenaltyRuleTypeDiscount, DiscountPercent: 20}
+	eResult := &EvaluationResult{PenaltyRules: []*PenaltyRule{rule}}
+	err := applyPenaltyRules(defaultCatalogue(), &Agreement{HasPenaltyRule: true, PenaltyRules: []*PenaltyRule{rule}}, nil, eResult)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jResult, err := json.Marshal(eResult)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wire struct {
+		PenaltyRule  *PenaltyRule   `json:"penaltyRule"`
+		PenaltyRules []*PenaltyRule `json:"penaltyRules"`
+	}
+	err = json.Unmarshal(jResult, &wire)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wire.PenaltyRule == nil || wire.PenaltyRule.DiscountPercent != 20 || len(wire.PenaltyRules) != 1 {
+		t.Errorf("evaluation result = %s, want penaltyRule and penaltyRules", jResult)
+	}
+}
+
+func TestValidatePenaltyRules(t *testing.T) {
+	c := defaultCatalogue()
+	items := []*AgreementItem{{Code: AgreementItemCodeViewGarden}}
+
+	tests := []struct {
+		name    string
+		rule    *PenaltyRule
+		wantErr bool
+	}{
+		{name: "discount", rule: &PenaltyRule{Type: PenaltyRuleTypeDiscount, DiscountPercent: 10}},
+		{name: "upgrade level", rule: &PenaltyRule{Type: PenaltyRuleTypeUpgradeLevel, UpgradeDimension: UpgradeDimensionView, UpgradeLevels: 1}},
+		{name: "legacy type", rule: &PenaltyRule{Type: "voucher", Amount: 25}},
+		{name: "upgrade without levels", rule: &PenaltyRule{Type: PenaltyRuleTypeUpgradeLevel, UpgradeDimension: UpgradeDimensionView}, wantErr: true},
+		{name: "upgrade without base", rule: &PenaltyRule{Type: PenaltyRuleTypeUpgradeLevel, UpgradeDimension: UpgradeDimensionBed, UpgradeLevels: 1}, wantErr: true},
+		{name: "unknown severity", rule: &PenaltyRule{Type: PenaltyRuleTypeDiscount, Severity: "fatal"}, wantErr: true},
+		{name: "empty delay range", rule: &PenaltyRule{Type: PenaltyRuleTypeDiscount, MinDelayMinutes: 30, MaxDelayMinutes: 30}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePenaltyRules(c, items, nil, []*PenaltyRule{tt.rule})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
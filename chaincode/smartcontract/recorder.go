@@ -0,0 +1,292 @@
+package smartcontract
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Recorder events
+const (
+	EventPenaltyRulesTriggered = "PenaltyRulesTriggered"
+)
+
+// evaluationRecorder records evaluations of agreements in one transaction. The world state does not
+// reflect writes of the transaction, so services are read once, evaluations of the same service
+// accumulate on the same copy and every recorded service is saved once by save.
+type evaluationRecorder struct {
+	contract  *EvaluationsContract
+	config    *ContractConfig
+	catalogue *catalogue
+
+	services    map[string]*Service
+	serviceIDs  []string
+	feedbacks   map[string]bool
+	evaluations map[string]bool
+
+	// penalties to be enforced by the platform once the transaction is committed
+	enforcements []*EnforcePenaltyRulesRequest
+}
+
+// evaluationRecord is a evaluation of a agreement to be recorded.
+type evaluationRecord struct {
+	evaluationType string
+	eid            string
+	rid            string
+	hash           string
+	at             string
+
+	// result of the evaluation, it's penalty rules are the triggered penalty
+	result *EvaluationResult
+}
+
+func newEvaluationRecorder(ctx contractapi.TransactionContextInterface, s *EvaluationsContract) (*evaluationRecorder, error) {
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c, err := loadCatalogue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &evaluationRecorder{
+		contract:    s,
+		config:      config,
+		catalogue:   c,
+		services:    make(map[string]*Service),
+		feedbacks:   make(map[string]bool),
+		evaluations: make(map[string]bool),
+	}, nil
+}
+
+// agreement returns the service and it's agreement with the given ids.
+func (r *evaluationRecorder) agreement(ctx contractapi.TransactionContextInterface, sid, aid string) (*Service, *Agreement, error) {
+	service, ok := r.services[sid]
+	if !ok {
+		var err error
+		service, err = readService(ctx, sid)
+		if err != nil {
+			return nil, nil, err
+		}
+		r.services[sid] = service
+	}
+
+	agreement, err := findAgreement(service, aid)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return service, agreement, nil
+}
+
+// record updates the satisfaction counters and rates of the agreement for the evaluation and writes the evaluation,
+// it's index entry, the feedback marker of a satisfaction evaluation and the compensation of the
+// triggered penalty. The service is saved by save.
+func (r *evaluationRecorder) record(ctx contractapi.TransactionContextInterface, service *Service, agreement *Agreement, rec *evaluationRecord) error {
+	if r.evaluations[rec.eid] {
+		return fmt.Errorf("the evaluation %s is submitted more than once", rec.eid)
+	}
+	// evaluations are stored at their id, a existing evaluation or any other document is never overwritten
+	exist, err := evaluationExists(ctx, rec.eid)
+	if err != nil {
+		return err
+	}
+	if exist {
+		return fmt.Errorf("the evaluation %s already exists", rec.eid)
+	}
+
+	evaluation := &Evaluation{
+		DocType:          "Evaluation",
+		EvaluationID:     rec.eid,
+		ServiceID:        service.ServiceID,
+		AgreementID:      agreement.AgreementID,
+		TxID:             ctx.GetStub().GetTxID(),
+		Hash:             rec.hash,
+		Type:             rec.evaluationType,
+		AgreementVersion: agreement.Version,
+		EvaluatedAt:      rec.at,
+	}
+
+	if rec.evaluationType != EvaluationTypeSLA && rec.evaluationType != EvaluationTypeSatisfaction {
+		return fmt.Errorf("evaluation type %s has not supported yet", rec.evaluationType)
+	}
+
+	eResult := rec.result
+	agreement.TotalFeedbacks++
+	if !eResult.Satisfied {
+		agreement.TotalUnsatisfied++
+	}
+	recordScore(r.config, agreement, rec.eid, eResult.Satisfied, eResult.Score)
+	refreshSatisfactionRate(r.config, service, agreement)
+
+	evaluation.Verdict = verdict(eResult.Satisfied)
+	evaluation.Score = eResult.Score
+	evaluation.Scored = true
+	evaluation.PenaltyRules = eResult.PenaltyRules
+	evaluation.DiscountPercent = eResult.DiscountPercent
+	evaluation.Amount = eResult.Amount
+	evaluation.UpgradeTarget = eResult.UpgradeTarget
+	evaluation.Severity = eResult.Severity
+	evaluation.FailureReason = eResult.FailureReason
+
+	agreement.LastEvaluationAt = rec.at
+	service.NumberOfEvaluations++
+	service.LastEvaluationAt = rec.at
+
+	jEvaluation, err := json.Marshal(evaluation)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(rec.eid, jEvaluation)
+	if err != nil {
+		return fmt.Errorf("fail to save evaluation %s", rec.eid)
+	}
+
+	docEvaluationIndexKey, err := ctx.GetStub().CreateCompositeKey(evaluationIndex, []string{evaluation.DocType, evaluation.EvaluationID})
+	if err != nil {
+		return err
+	}
+	//  Save evaluationIndex entry to world state. Only the key name is needed, no need to store a duplicate copy of the evaluation.
+	//  Note - passing a 'nil' value will effectively delete the key from state, therefore we pass null character as value
+	value := []byte{0x00}
+	err = ctx.GetStub().PutState(docEvaluationIndexKey, value)
+	if err != nil {
+		return err
+	}
+
+	if rec.evaluationType == EvaluationTypeSatisfaction {
+		// Mark the agreement as evaluated by the reservation so that duplicated feedbacks are rejected.
+		feedbackIndexKey, err := ctx.GetStub().CreateCompositeKey(feedbackIndex, []string{service.ServiceID, rec.rid, agreement.AgreementID})
+		if err != nil {
+			return err
+		}
+		err = ctx.GetStub().PutState(feedbackIndexKey, value)
+		if err != nil {
+			return err
+		}
+		r.feedbacks[feedbackKey(service.ServiceID, rec.rid, agreement.AgreementID)] = true
+	}
+
+	if r.config.CompensationsEnabled && !eResult.Satisfied && len(eResult.PenaltyRules) > 0 {
+		_, err = r.contract.createCompensation(ctx, r.config, service.ServiceID, agreement.AgreementID, rec.eid, rec.rid, eResult, rec.at)
+		if err != nil {
+			return err
+		}
+	}
+
+	r.evaluations[rec.eid] = true
+	if !StringInSlice(service.ServiceID, r.serviceIDs) {
+		r.serviceIDs = append(r.serviceIDs, service.ServiceID)
+	}
+
+	return nil
+}
+
+// settle settles a pending compensation of a agreement as paid or waived with the evidence hash at the
+// time of the transaction and recounts the rule violations of the agreement. Only the provider of the
+// service can mark it paid and only an arbitrator or an admin of the platform org can waive it. The service
+// is saved by save.
+func (r *evaluationRecorder) settle(ctx contractapi.TransactionContextInterface, compensation *Compensation, status, evidenceHash string) error {
+	if status != CompensationStatusPaid && status != CompensationStatusWaived {
+		return fmt.Errorf("the compensation can not be settled with status %s", status)
+	}
+	if evidenceHash == "" {
+		return fmt.Errorf("the evidence hash must not be empty")
+	}
+
+	var err error
+	if status == CompensationStatusPaid {
+		err = assertServiceOwner(ctx, compensation.ServiceID)
+	} else {
+		err = assertPlatformRole(ctx, r.config, RoleArbitrator, RoleAdmin)
+	}
+	if err != nil {
+		return err
+	}
+
+	if compensation.Status != CompensationStatusPending {
+		return fmt.Errorf("the compensation %s is %s, only pending compensations can be settled", compensation.CompensationID, compensation.Status)
+	}
+
+	service, agreement, err := r.agreement(ctx, compensation.ServiceID, compensation.AgreementID)
+	if err != nil {
+		return err
+	}
+
+	settledAt, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+	at := settledAt.Format(RFC3339)
+	dueAt, err := ParseTime(compensation.DueAt)
+	if err != nil {
+		return err
+	}
+
+	if settledAt.After(dueAt) {
+		compensation.Overdue = true
+	}
+	compensation.Status = status
+	compensation.EvidenceHash = evidenceHash
+	compensation.SettledAt = at
+	compensation.TxID = ctx.GetStub().GetTxID()
+
+	err = r.contract.refreshRuleAbidingCounts(ctx, service, agreement, compensation)
+	if err != nil {
+		return err
+	}
+	agreement.LastEvaluationAt = at
+	service.LastEvaluationAt = at
+
+	err = r.contract.putCompensation(ctx, compensation)
+	if err != nil {
+		return err
+	}
+
+	if !StringInSlice(service.ServiceID, r.serviceIDs) {
+		r.serviceIDs = append(r.serviceIDs, service.ServiceID)
+	}
+
+	return nil
+}
+
+// feedbackKey returns the key of the feedback of a reservation for a agreement of a service in a recorder.
+func feedbackKey(sid, rid, aid string) string {
+	return sid + "~" + rid + "~" + aid
+}
+
+// enforce requests the platform to enforce the penalty triggered by a recorded evaluation.
+func (r *evaluationRecorder) enforce(req *EnforcePenaltyRulesRequest) {
+	r.enforcements = append(r.enforcements, req)
+}
+
+// save writes every service which has recorded evaluations to the world state and emits one
+// PenaltyRulesTriggered event with every penalty to be enforced. Penalties are not enforced in
+// the transaction, the endpoint is called by the platform after the event.
+func (r *evaluationRecorder) save(ctx contractapi.TransactionContextInterface) error {
+	for _, sid := range r.serviceIDs {
+		jService, err := json.Marshal(r.services[sid])
+		if err != nil {
+			return err
+		}
+		err = ctx.GetStub().PutState(sid, jService)
+		if err != nil {
+			return fmt.Errorf("fail to update rates for service %s", sid)
+		}
+	}
+
+	if len(r.enforcements) == 0 {
+		return nil
+	}
+	jEvent, err := json.Marshal(&PenaltyRulesTriggeredEvent{
+		TxID:     ctx.GetStub().GetTxID(),
+		Requests: r.enforcements,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().SetEvent(EventPenaltyRulesTriggered, jEvent)
+}
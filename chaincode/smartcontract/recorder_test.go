@@ -0,0 +1,416 @@
+package smartcontract
+
+import (
+	b64 "encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const (
+	recorderServiceID   = "service1"
+	recorderAgreementID = "agreement1"
+	recorderAt          = "2021-05-01T12:00:00.000Z"
+)
+
+// shuttleService returns a service with a airport shuttle agreement which has penalty rules.
+func shuttleService(sid string) *Service {
+	return &Service{
+		DocType:   "Service",
+		ServiceID: sid,
+		Agreements: []*Agreement{{
+			AgreementID: recorderAgreementID,
+			Category:    AgreementCategoryService,
+			Version:     2,
+			Items: []*AgreementItem{{
+				Code:                  AgreementItemCodeServiceAirportShuttle,
+				DriverMaxWaitTime:     30,
+				CustomerShortWaitTime: 5,
+				CustomerLongWaitTime:  15,
+			}},
+			HasPenaltyRule: true,
+			PenaltyRules: []*PenaltyRule{
+				{Type: PenaltyRuleTypeDiscount, DiscountPercent: 100},
+				{Type: PenaltyRuleTypeDiscount, DiscountPercent: 20},
+				{Type: PenaltyRuleTypeDiscount, DiscountPercent: 50},
+			},
+		}},
+	}
+}
+
+func shuttleEvaluationData(driverArriveAt string) string {
+	data := `[{"code":"` + AgreementItemCodeServiceAirportShuttle + `","status":"` + AirportShuttleStatusCompleted +
+		`","pickUpTime":"2021-05-01T10:00:00Z","driverArriveAt":"` + driverArriveAt + `"}]`
+	return b64.StdEncoding.EncodeToString([]byte(data))
+}
+
+func TestEvaluationRecorder(t *testing.T) {
+	s := &EvaluationsContract{}
+
+	tests := []struct {
+		name   string
+		record func(ctx contractapi.TransactionContextInterface) error
+
+		wantErr          string
+		evaluationType   string
+		verdict          string
+		scored           bool
+		totalFeedbacks   uint
+		totalUnsatisfied uint
+		satisfactionRate float32
+		penalty          bool
+		feedbackMarker   bool
+	}{
+		{
+			name: "sla satisfied",
+			record: func(ctx contractapi.TransactionContextInterface) error {
+				_, err := s.EvaluateSLA(ctx, recorderServiceID, recorderAgreementID, "e1", shuttleEvaluationData("2021-05-01T10:05:00Z"), "hash", recorderAt)
+				return err
+			},
+			evaluationType:   EvaluationTypeSLA,
+			verdict:          EvaluationVerdictSatisfied,
+			scored:           true,
+			totalFeedbacks:   1,
+			satisfactionRate: 1,
+		},
+		{
+			name: "sla unsatisfied",
+			record: func(ctx contractapi.TransactionContextInterface) error {
+				_, err := s.EvaluateSLA(ctx, recorderServiceID, recorderAgreementID, "e1", shuttleEvaluationData("2021-05-01T10:20:00Z"), "hash", recorderAt)
+				return err
+			},
+			evaluationType:   EvaluationTypeSLA,
+			verdict:          EvaluationVerdictUnsatisfied,
+			scored:           true,
+			totalFeedbacks:   1,
+			totalUnsatisfied: 1,
+			penalty:          true,
+		},
+		{
+			name: "sla of missing service",
+			record: func(ctx contractapi.TransactionContextInterface) error {
+				_, err := s.EvaluateSLA(ctx, "missing", recorderAgreementID, "e1", shuttleEvaluationData("2021-05-01T10:05:00Z"), "hash", recorderAt)
+				return err
+			},
+			wantErr: "the service missing does not exist",
+		},
+		{
+			name: "sla of missing agreement",
+			record: func(ctx contractapi.TransactionContextInterface) error {
+				_, err := s.EvaluateSLA(ctx, recorderServiceID, "agreement2", "e1", shuttleEvaluationData("2021-05-01T10:05:00Z"), "hash", recorderAt)
+				return err
+			},
+			wantErr: "the agreement agreement2 does not exist",
+		},
+		{
+			name: "sla evaluated twice",
+			record: func(ctx contractapi.TransactionContextInterface) error {
+				_, err := s.EvaluateSLA(ctx, recorderServiceID, recorderAgreementID, "e1", shuttleEvaluationData("2021-05-01T10:05:00Z"), "hash", recorderAt)
+				if err != nil {
+					return err
+				}
+				_, err = s.EvaluateSLA(ctx, recorderServiceID, recorderAgreementID, "e1", shuttleEvaluationData("2021-05-01T10:05:00Z"), "hash", recorderAt)
+				return err
+			},
+			wantErr: "the evaluation e1 already exists",
+		},
+		{
+			name: "satisfaction satisfied",
+			record: func(ctx contractapi.TransactionContextInterface) error {
+				_, err := s.HandleSatisfactionEvaluationEvent(ctx, recorderServiceID, recorderAgreementID, "e1", "r1", "hash", recorderAt, true, true)
+				return err
+			},
+			evaluationType:   EvaluationTypeSatisfaction,
+			verdict:          EvaluationVerdictSatisfied,
+			scored:           true,
+			totalFeedbacks:   1,
+			satisfactionRate: 1,
+			feedbackMarker:   true,
+		},
+		{
+			name: "satisfaction unsatisfied with penalty",
+			record: func(ctx contractapi.TransactionContextInterface) error {
+				_, err := s.HandleSatisfactionEvaluationEvent(ctx, recorderServiceID, recorderAgreementID, "e1", "r1", "hash", recorderAt, false, true)
+				return err
+			},
+			evaluationType:   EvaluationTypeSatisfaction,
+			verdict:          EvaluationVerdictUnsatisfied,
+			scored:           true,
+			totalFeedbacks:   1,
+			totalUnsatisfied: 1,
+			penalty:          true,
+			feedbackMarker:   true,
+		},
+		{
+			name: "satisfaction unsatisfied without penalty",
+			record: func(ctx contractapi.TransactionContextInterface) error {
+				_, err := s.HandleSatisfactionEvaluationEvent(ctx, recorderServiceID, recorderAgreementID, "e1", "r1", "hash", recorderAt, false, false)
+				return err
+			},
+			evaluationType:   EvaluationTypeSatisfaction,
+			verdict:          EvaluationVerdictUnsatisfied,
+			scored:           true,
+			totalFeedbacks:   1,
+			totalUnsatisfied: 1,
+			feedbackMarker:   true,
+		},
+		{
+			name: "satisfaction evaluated twice by reservation",
+			record: func(ctx contractapi.TransactionContextInterface) error {
+				_, err := s.HandleSatisfactionEvaluationEvent(ctx, recorderServiceID, recorderAgreementID, "e1", "r1", "hash", recorderAt, true, true)
+				if err != nil {
+					return err
+				}
+				_, err = s.HandleSatisfactionEvaluationEvent(ctx, recorderServiceID, recorderAgreementID, "e2", "r1", "hash", recorderAt, true, true)
+				return err
+			},
+			wantErr: "the reservation r1 has already evaluated the agreement agreement1",
+		},
+		{
+			name: "satisfaction of missing service",
+			record: func(ctx contractapi.TransactionContextInterface) error {
+				_, err := s.HandleSatisfactionEvaluationEvent(ctx, "missing", recorderAgreementID, "e1", "r1", "hash", recorderAt, true, true)
+				return err
+			},
+			wantErr: "the service missing does not exist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, stub := newTestContext(t)
+			putTestService(t, stub, shuttleService(recorderServiceID))
+
+			err := tt.record(ctx)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			service, err := readService(ctx, recorderServiceID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			agreement := service.Agreements[0]
+			if service.NumberOfEvaluations != 1 {
+				t.Errorf("number of evaluations = %d, want 1", service.NumberOfEvaluations)
+			}
+			if service.LastEvaluationAt != recorderAt || agreement.LastEvaluationAt != recorderAt {
+				t.Errorf("last evaluation at = %s/%s, want %s", service.LastEvaluationAt, agreement.LastEvaluationAt, recorderAt)
+			}
+			if agreement.TotalFeedbacks != tt.totalFeedbacks || agreement.TotalUnsatisfied != tt.totalUnsatisfied {
+				t.Errorf("feedbacks = %d/%d, want %d/%d", agreement.TotalFeedbacks, agreement.TotalUnsatisfied, tt.totalFeedbacks, tt.totalUnsatisfied)
+			}
+			if tt.totalFeedbacks > 0 && service.SatisfactionRate != tt.satisfactionRate {
+				t.Errorf("satisfaction rate = %v, want %v", service.SatisfactionRate, tt.satisfactionRate)
+			}
+
+			evaluation, err := s.ReadEvaluation(ctx, "e1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if evaluation.Type != tt.evaluationType || evaluation.Verdict != tt.verdict || evaluation.Scored != tt.scored {
+				t.Errorf("evaluation = %s/%s/%v, want %s/%s/%v", evaluation.Type, evaluation.Verdict, evaluation.Scored, tt.evaluationType, tt.verdict, tt.scored)
+			}
+			if evaluation.AgreementVersion != 2 || evaluation.EvaluatedAt != recorderAt || evaluation.TxID != "tx1" {
+				t.Errorf("evaluation recorded version %d at %s in %s", evaluation.AgreementVersion, evaluation.EvaluatedAt, evaluation.TxID)
+			}
+			if (len(evaluation.PenaltyRules) > 0) != tt.penalty {
+				t.Errorf("evaluation penalty rules = %v, want penalty %v", evaluation.PenaltyRules, tt.penalty)
+			}
+
+			indexKey, _ := ctx.GetStub().CreateCompositeKey(evaluationIndex, []string{"Evaluation", "e1"})
+			index, _ := ctx.GetStub().GetState(indexKey)
+			if index == nil {
+				t.Error("evaluation index entry is not written")
+			}
+
+			evaluated, err := s.HasReservationEvaluatedAgreement(ctx, recorderServiceID, "r1", recorderAgreementID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if evaluated != tt.feedbackMarker {
+				t.Errorf("feedback marker = %v, want %v", evaluated, tt.feedbackMarker)
+			}
+
+			exist, err := s.CompensationExists(ctx, "e1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if exist != tt.penalty {
+				t.Errorf("compensation exists = %v, want %v", exist, tt.penalty)
+			}
+		})
+	}
+}
+
+func TestEvaluationRecorderBatch(t *testing.T) {
+	s := &EvaluationsContract{}
+	ctx, stub := newTestContext(t)
+	putTestService(t, stub, shuttleService(recorderServiceID))
+
+	submissions := []*EvaluationSubmission{
+		{ServiceID: recorderServiceID, AgreementID: recorderAgreementID, EvaluationID: "e1", ReservationID: "r1", At: recorderAt, Satisfied: true},
+		{ServiceID: recorderServiceID, AgreementID: recorderAgreementID, EvaluationID: "e2", ReservationID: "r2", At: recorderAt, Satisfied: false},
+		{ServiceID: recorderServiceID, AgreementID: recorderAgreementID, EvaluationID: "e3", ReservationID: "r1", At: recorderAt, Satisfied: true},
+	}
+	jSubmissions, err := json.Marshal(submissions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := s.SubmitEvaluationBatch(ctx, b64.StdEncoding.EncodeToString(jSubmissions), BatchModeBestEffort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !results[0].Recorded || !results[1].Recorded || results[2].Recorded {
+		t.Fatalf("recorded = %v/%v/%v, want true/true/false", results[0].Recorded, results[1].Recorded, results[2].Recorded)
+	}
+
+	service, err := readService(ctx, recorderServiceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agreement := service.Agreements[0]
+	if service.NumberOfEvaluations != 2 || agreement.TotalFeedbacks != 2 || agreement.TotalUnsatisfied != 1 {
+		t.Errorf("evaluations = %d, feedbacks = %d/%d, want 2, 2/1", service.NumberOfEvaluations, agreement.TotalFeedbacks, agreement.TotalUnsatisfied)
+	}
+	if service.SatisfactionRate != 0.5 {
+		t.Errorf("satisfaction rate = %v, want 0.5", service.SatisfactionRate)
+	}
+}
+
+func TestFeedbackMarkerPerService(t *testing.T) {
+	s := &EvaluationsContract{}
+	ctx, stub := newTestContext(t)
+	putTestService(t, stub, shuttleService(recorderServiceID))
+	putTestService(t, stub, shuttleService("service2"))
+
+	_, err := s.HandleSatisfactionEvaluationEvent(ctx, recorderServiceID, recorderAgreementID, "e1", "r1", "hash", recorderAt, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the agreement of the other service has the same id but has not been evaluated by the reservation
+	_, err = s.HandleSatisfactionEvaluationEvent(ctx, "service2", recorderAgreementID, "e2", "r1", "hash", recorderAt, true, false)
+	if err != nil {
+		t.Fatalf("feedback for another service is rejected: %v", err)
+	}
+
+	_, err = s.HandleSatisfactionEvaluationEvent(ctx, "service2", recorderAgreementID, "e3", "r1", "hash", recorderAt, true, false)
+	if err == nil {
+		t.Fatal("duplicated feedback for the same service is accepted")
+	}
+}
+
+func TestPenaltyRulesTriggeredEvent(t *testing.T) {
+	tests := []struct {
+		name               string
+		enforcementEnabled bool
+		satisfied          bool
+		enforcePenaltyRule bool
+		wantEvent          bool
+	}{
+		{name: "unsatisfied with penalty", enforcementEnabled: true, enforcePenaltyRule: true, wantEvent: true},
+		{name: "unsatisfied without penalty", enforcementEnabled: true},
+		{name: "satisfied", enforcementEnabled: true, satisfied: true, enforcePenaltyRule: true},
+		{name: "enforcement disabled", enforcePenaltyRule: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &EvaluationsContract{}
+			ctx, stub := newTestContext(t)
+			putTestService(t, stub, shuttleService(recorderServiceID))
+			config := defaultConfig()
+			config.PenaltyEnforcementEnabled = tt.enforcementEnabled
+			jConfig, err := json.Marshal(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = stub.PutState(contractConfigKey, jConfig)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the penalty is not enforced in the transaction, peers without the access key record it as well
+			_, err = s.HandleSatisfactionEvaluationEvent(ctx, recorderServiceID, recorderAgreementID, "e1", "r1", "hash", recorderAt, tt.satisfied, tt.enforcePenaltyRule)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			events := chaincodeEvents(stub)
+			if !tt.wantEvent {
+				if len(events) > 0 {
+					t.Errorf("events = %v, want no event", events)
+				}
+				return
+			}
+			payloads := events[EventPenaltyRulesTriggered]
+			if len(events) != 1 || len(payloads) != 1 {
+				t.Fatalf("events = %v, want one %s event", events, EventPenaltyRulesTriggered)
+			}
+			var event PenaltyRulesTriggeredEvent
+			err = json.Unmarshal(payloads[0], &event)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if event.TxID != "tx1" || len(event.Requests) != 1 {
+				t.Fatalf("event = %+v, want one request of tx1", event)
+			}
+			req := event.Requests[0]
+			if req.EvaluationID != "e1" || req.ReservationID != "r1" || req.AgreementID != recorderAgreementID || len(req.PenaltyRules) == 0 {
+				t.Errorf("request = %+v, want the triggered penalty of e1", req)
+			}
+		})
+	}
+}
+
+func TestEnforcePenaltyRuleFromBlockChain(t *testing.T) {
+	tests := []struct {
+		name               string
+		role               string
+		clientMSPID        string
+		peerMSPID          string
+		enforcementEnabled bool
+		wantErr            string
+	}{
+		{name: "provider", role: RoleProvider, peerMSPID: DefaultPlatformMSPID, enforcementEnabled: true, wantErr: "the client must have one of roles [admin]"},
+		{name: "admin of another org", role: RoleAdmin, clientMSPID: "Org2MSP", peerMSPID: DefaultPlatformMSPID, enforcementEnabled: true, wantErr: "the client must belong to the platform org"},
+		{name: "peer of another org", role: RoleAdmin, peerMSPID: "Org2MSP", enforcementEnabled: true, wantErr: "the peer must belong to the platform org"},
+		{name: "enforcement disabled", role: RoleAdmin, peerMSPID: DefaultPlatformMSPID, wantErr: "penalty enforcement is disabled"},
+		{name: "no access key", role: RoleAdmin, peerMSPID: DefaultPlatformMSPID, enforcementEnabled: true, wantErr: "the internal service access key does not exist"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer setPeerMSPID(tt.peerMSPID)()
+
+			ctx, stub := newTestContext(t)
+			putTestService(t, stub, shuttleService(recorderServiceID))
+			config := defaultConfig()
+			config.PenaltyEnforcementEnabled = tt.enforcementEnabled
+			jConfig, err := json.Marshal(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = ctx.GetStub().PutState(contractConfigKey, jConfig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx.SetClientIdentity(&testIdentity{id: tt.role, mspID: tt.clientMSPID, attrs: map[string]string{IdentityAttributeRole: tt.role}})
+
+			err = (&EvaluationsContract{}).EnforcePenaltyRuleFromBlockChain(ctx, "r1", recorderAgreementID, &EvaluationResult{}, "")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package smartcontract
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// validateSaunaItem validates the verification mode and it's thresholds of a sauna item.
+func validateSaunaItem(item *AgreementItem) error {
+	switch item.SaunaMode {
+	case "", SaunaModeFailures:
+		if item.MaxFailures <= 0 {
+			return fmt.Errorf("max failures of agreement item %s must be positive", item.Code)
+		}
+		if item.MinTimeBetween2Failures < 0 {
+			return fmt.Errorf("min time between 2 failures of agreement item %s must not be negative", item.Code)
+		}
+	case SaunaModeAvailability:
+		if item.MinSuccessRatio <= 0 || item.MinSuccessRatio > 1 {
+			return fmt.Errorf("min success ratio of agreement item %s must be greater than 0 and at most 1", item.Code)
+		}
+	default:
+		return fmt.Errorf("sauna mode %s has not supported yet", item.SaunaMode)
+	}
+
+	return nil
+}
+
+// VerifySaunaAgreement verify sauna agreement.
+//
+// In failures mode the agreement is unsatisfied when the number of counted failures reaches
+// MaxFailures. The first failure is always counted, a later failure is counted only when it is
+// at least MinTimeBetween2Failures minutes after the last counted one, failures within that
+// window belong to the same outage.
+//
+// In availability mode the agreement is unsatisfied when the ratio of successful requests over
+// the stay is below MinSuccessRatio.
+func (s *EvaluationsContract) VerifySaunaAgreement(_ contractapi.TransactionContextInterface, a *Agreement, data *EvaluationData) (*EvaluationResult, error) {
+	p, ok := payloadOf(AgreementCategoryService, data).(*saunaPayload)
+	if !ok {
+		return nil, fmt.Errorf("evaluation data code %s is not sauna service item code", data.Code)
+	}
+
+	return verifySaunaAgreement(a, p)
+}
+
+// verifySaunaAgreement verifies sauna agreement against the sauna payload.
+func verifySaunaAgreement(a *Agreement, data *saunaPayload) (*EvaluationResult, error) {
+	agreementItem := a.Items[0]
+	requests := make([]*SaunaRequest, len(data.SaunaRequests))
+	copy(requests, data.SaunaRequests)
+
+	// sort requests by request time
+	sort.SliceStable(requests, func(i, j int) bool {
+		return requests[i].RequestAt.Before(requests[j].RequestAt)
+	})
+
+	numFailures := countSaunaFailures(requests, agreementItem.MinTimeBetween2Failures)
+	score := saunaScore(requests)
+
+	var satisfied bool
+	var reason string
+	switch agreementItem.SaunaMode {
+	case "", SaunaModeFailures:
+		// agreements stored before max failures were validated allow no failure
+		maxFailures := agreementItem.MaxFailures
+		if maxFailures <= 0 {
+			maxFailures = 1
+		}
+		satisfied = numFailures < maxFailures
+		reason = fmt.Sprintf("sauna failed %d times, the agreement allows %d failures at most", numFailures, maxFailures-1)
+	case SaunaModeAvailability:
+		satisfied = float64(score) >= agreementItem.MinSuccessRatio
+		reason = fmt.Sprintf("sauna was available for %.2f of requests but %.2f was agreed", score, agreementItem.MinSuccessRatio)
+	default:
+		return nil, fmt.Errorf("sauna mode %s has not supported yet", agreementItem.SaunaMode)
+	}
+
+	if satisfied {
+		return &EvaluationResult{
+			Satisfied: true,
+			Failures:  numFailures,
+			Score:     score,
+		}, nil
+	}
+
+	return &EvaluationResult{
+		Satisfied:     false,
+		PenaltyRules:  defaultPenaltyRules(a, 0),
+		FailureReason: reason,
+		Failures:      numFailures,
+		Score:         score,
+	}, nil
+}
+
+// countSaunaFailures counts failed requests sorted by request time. Failures within the given
+// minutes after the last counted failure are clustered into it.
+func countSaunaFailures(requests []*SaunaRequest, minTimeBetween2Failures int) int {
+	idx := -1
+	numFailures := 0
+	for i, req := range requests {
+		if req.Status != SaunaRequestStatusFail {
+			continue
+		}
+
+		if idx >= 0 && req.RequestAt.Sub(requests[idx].RequestAt).Minutes() < float64(minTimeBetween2Failures) {
+			continue
+		}
+
+		numFailures++
+		idx = i
+	}
+
+	return numFailures
+}
+
+// saunaScore scores sauna requests by the ratio of successful requests.
+func saunaScore(requests []*SaunaRequest) float32 {
+	if len(requests) == 0 {
+		return 1
+	}
+
+	successes := 0
+	for _, req := range requests {
+		if req.Status == SaunaRequestStatusSuccess {
+			successes++
+		}
+	}
+	return float32(successes) / float32(len(requests))
+}
@@ -0,0 +1,169 @@
+package smartcontract
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerifySaunaAgreement(t *testing.T) {
+	start := time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC)
+	request := func(minutes int, status string) *SaunaRequest {
+		return &SaunaRequest{RequestAt: start.Add(time.Duration(minutes) * time.Minute), Status: status}
+	}
+	success := func(minutes int) *SaunaRequest { return request(minutes, SaunaRequestStatusSuccess) }
+	fail := func(minutes int) *SaunaRequest { return request(minutes, SaunaRequestStatusFail) }
+
+	agreement := func(item *AgreementItem) *Agreement {
+		item.Code = AgreementItemCodeServiceSauna
+		return &Agreement{
+			Category:       AgreementCategoryService,
+			Items:          []*AgreementItem{item},
+			HasPenaltyRule: true,
+			PenaltyRules:   []*PenaltyRule{{Type: PenaltyRuleTypeDiscount, DiscountPercent: 10}},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		item      *AgreementItem
+		requests  []*SaunaRequest
+		wantErr   bool
+		satisfied bool
+		failures  int
+		score     float32
+	}{
+		{
+			name:      "no failure",
+			item:      &AgreementItem{MaxFailures: 1},
+			requests:  []*SaunaRequest{success(0), success(60)},
+			satisfied: true,
+			score:     1,
+		},
+		{
+			name:     "one failure with max failures 1",
+			item:     &AgreementItem{MaxFailures: 1},
+			requests: []*SaunaRequest{success(0), fail(60)},
+			failures: 1,
+			score:    0.5,
+		},
+		{
+			name:     "one failure of a stored agreement without max failures",
+			item:     &AgreementItem{},
+			requests: []*SaunaRequest{fail(0)},
+			failures: 1,
+			score:    0,
+		},
+		{
+			name:      "failures below max failures",
+			item:      &AgreementItem{MaxFailures: 3},
+			requests:  []*SaunaRequest{fail(0), success(30), fail(60)},
+			satisfied: true,
+			failures:  2,
+			score:     float32(1) / 3,
+		},
+		{
+			name:     "failures reach max failures",
+			item:     &AgreementItem{MaxFailures: 2, SaunaMode: SaunaModeFailures},
+			requests: []*SaunaRequest{fail(0), fail(60)},
+			failures: 2,
+		},
+		{
+			name:      "failures within the cluster window are one outage",
+			item:      &AgreementItem{MaxFailures: 2, MinTimeBetween2Failures: 30},
+			requests:  []*SaunaRequest{fail(0), fail(10), fail(29)},
+			satisfied: true,
+			failures:  1,
+		},
+		{
+			name:     "failure at the end of the cluster window is counted",
+			item:     &AgreementItem{MaxFailures: 2, MinTimeBetween2Failures: 30},
+			requests: []*SaunaRequest{fail(0), fail(10), fail(30)},
+			failures: 2,
+		},
+		{
+			name:     "cluster window starts at the last counted failure",
+			item:     &AgreementItem{MaxFailures: 3, MinTimeBetween2Failures: 30},
+			requests: []*SaunaRequest{fail(50), fail(0), fail(20), fail(40), fail(70)},
+			failures: 3,
+		},
+		{
+			name:      "availability reaches min success ratio",
+			item:      &AgreementItem{SaunaMode: SaunaModeAvailability, MinSuccessRatio: 0.75},
+			requests:  []*SaunaRequest{success(0), success(10), success(20), fail(30)},
+			satisfied: true,
+			failures:  1,
+			score:     0.75,
+		},
+		{
+			name:     "availability below min success ratio",
+			item:     &AgreementItem{SaunaMode: SaunaModeAvailability, MinSuccessRatio: 0.75},
+			requests: []*SaunaRequest{success(0), success(10), fail(20), fail(60)},
+			failures: 2,
+			score:    0.5,
+		},
+		{
+			name:      "availability without requests",
+			item:      &AgreementItem{SaunaMode: SaunaModeAvailability, MinSuccessRatio: 1},
+			satisfied: true,
+			score:     1,
+		},
+		{
+			name:     "unknown mode",
+			item:     &AgreementItem{SaunaMode: "uptime"},
+			requests: []*SaunaRequest{success(0)},
+			wantErr:  true,
+		},
+	}
+
+	s := &EvaluationsContract{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := &EvaluationData{Code: AgreementItemCodeServiceSauna, SaunaRequests: tt.requests}
+			eResult, err := s.VerifySaunaAgreement(nil, agreement(tt.item), data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if eResult.Satisfied != tt.satisfied {
+				t.Errorf("satisfied = %v, want %v", eResult.Satisfied, tt.satisfied)
+			}
+			if eResult.Failures != tt.failures {
+				t.Errorf("failures = %d, want %d", eResult.Failures, tt.failures)
+			}
+			if !floatEqual(eResult.Score, tt.score) {
+				t.Errorf("score = %v, want %v", eResult.Score, tt.score)
+			}
+			if !eResult.Satisfied && len(eResult.PenaltyRules) == 0 {
+				t.Errorf("unsatisfied result has no penalty rule")
+			}
+		})
+	}
+}
+
+func TestValidateSaunaItem(t *testing.T) {
+	tests := []struct {
+		name    string
+		item    *AgreementItem
+		wantErr bool
+	}{
+		{name: "failures", item: &AgreementItem{MaxFailures: 1, MinTimeBetween2Failures: 30}},
+		{name: "failures without max failures", item: &AgreementItem{SaunaMode: SaunaModeFailures}, wantErr: true},
+		{name: "negative cluster window", item: &AgreementItem{MaxFailures: 1, MinTimeBetween2Failures: -1}, wantErr: true},
+		{name: "availability", item: &AgreementItem{SaunaMode: SaunaModeAvailability, MinSuccessRatio: 1}},
+		{name: "availability without ratio", item: &AgreementItem{SaunaMode: SaunaModeAvailability}, wantErr: true},
+		{name: "availability over 1", item: &AgreementItem{SaunaMode: SaunaModeAvailability, MinSuccessRatio: 1.5}, wantErr: true},
+		{name: "unknown mode", item: &AgreementItem{SaunaMode: "uptime", MaxFailures: 1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSaunaItem(tt.item)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
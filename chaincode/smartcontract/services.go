@@ -0,0 +1,141 @@
+package smartcontract
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// CreateService issues a new service to the world state with given details.
+func (s *ServicesContract) CreateService(ctx contractapi.TransactionContextInterface, id string) error {
+	exist, err := serviceExists(ctx, id)
+	if err != nil {
+		return err
+	}
+	if exist {
+		return fmt.Errorf("the service %s already exists", id)
+	}
+
+	service := Service{
+		DocType:          "Service",
+		ServiceID:        id,
+		SatisfactionRate: 1,
+		RuleAbidingRate:  1,
+		Agreements:       []*Agreement{},
+		SchemaVersion:    SchemaVersion,
+	}
+
+	jService, err := json.Marshal(service)
+	if err != nil {
+		return err
+	}
+
+	err = ctx.GetStub().PutState(id, jService)
+	if err != nil {
+		return fmt.Errorf("can not create service: %s", id)
+	}
+
+	docServiceIndexKey, err := ctx.GetStub().CreateCompositeKey(serviceIndex, []string{service.DocType, service.ServiceID})
+	if err != nil {
+		return err
+	}
+	//  Save serviceIndex entry to world state. Only the key name is needed, no need to store a duplicate copy of the service.
+	//  Note - passing a 'nil' value will effectively delete the key from state, therefore we pass null character as value
+	value := []byte{0x00}
+	return ctx.GetStub().PutState(docServiceIndexKey, value)
+}
+
+// ReadService returns the service stored in the world state with given id.
+func (s *ServicesContract) ReadService(ctx contractapi.TransactionContextInterface, id string) (*Service, error) {
+	return readService(ctx, id)
+}
+
+// DeleteService deletes an given provider from the world state.
+func (s *ServicesContract) DeleteService(ctx contractapi.TransactionContextInterface, id string) error {
+	service, err := readService(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = ctx.GetStub().DelState(id)
+	if err != nil {
+		return fmt.Errorf("can not delete the service %s", id)
+	}
+
+	docServiceIndexKey, err := ctx.GetStub().CreateCompositeKey(serviceIndex, []string{service.DocType, service.ServiceID})
+	if err != nil {
+		return err
+	}
+
+	// Delete serviceIndex entry
+	return ctx.GetStub().DelState(docServiceIndexKey)
+}
+
+// GetAllServices returns all services found in world state.
+func (s *ServicesContract) GetAllServices(ctx contractapi.TransactionContextInterface) ([]*Service, error) {
+	serviceResultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(serviceIndex, []string{"Service"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+
+	defer serviceResultsIterator.Close()
+
+	var services []*Service
+	for serviceResultsIterator.HasNext() {
+		rangeResponse, err := serviceResultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(rangeResponse.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(compositeKeyParts) > 1 {
+			returnedServiceID := compositeKeyParts[1]
+			service, err := readService(ctx, returnedServiceID)
+			if err != nil {
+				return nil, err
+			}
+			services = append(services, service)
+		}
+	}
+
+	return services, nil
+}
+
+// ServiceExists returns true when service with given id exists in world state.
+func (s *ServicesContract) ServiceExists(ctx contractapi.TransactionContextInterface, id string) (bool, error) {
+	return serviceExists(ctx, id)
+}
+
+// readService returns the service stored in the world state with given id.
+func readService(ctx contractapi.TransactionContextInterface, id string) (*Service, error) {
+	jService, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return nil, err
+	}
+	if jService == nil {
+		return nil, fmt.Errorf("the service %s does not exist", id)
+	}
+
+	var service Service
+	err = json.Unmarshal(jService, &service)
+	if err != nil {
+		return nil, err
+	}
+
+	return &service, nil
+}
+
+// serviceExists returns true when service with given id exists in world state.
+func serviceExists(ctx contractapi.TransactionContextInterface, id string) (bool, error) {
+	exist, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return false, fmt.Errorf("failed to read service from world state: %v", err)
+	}
+
+	return exist != nil, nil
+}
@@ -0,0 +1,192 @@
+package smartcontract
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// shuttleTimeline is the pickup timeline of an airport shuttle reconstructed from evaluation data.
+type shuttleTimeline struct {
+	// expectedAt is the pick up time, or the arrival time last updated by the customer
+	expectedAt time.Time
+	// readyAt is the time the customer is ready to be picked up, the expected time
+	// or the customer check-in time, whichever is later
+	readyAt           time.Time
+	driverArriveAt    time.Time
+	customerCheckInAt time.Time
+	noShowNotifiedAt  time.Time
+}
+
+// newShuttleTimeline reconstructs the pickup timeline from evaluation data.
+func newShuttleTimeline(data *airportShuttlePayload) (*shuttleTimeline, error) {
+	if data.PickUpTime.IsZero() {
+		return nil, fmt.Errorf("pick up time of airport shuttle is missing")
+	}
+
+	t := &shuttleTimeline{
+		expectedAt:        data.PickUpTime,
+		driverArriveAt:    data.DriverArriveAt,
+		customerCheckInAt: data.CustomerCheckInAt,
+		noShowNotifiedAt:  data.DriverNotifyCustomerDoNotShowUpAt,
+	}
+	if !data.LastUpdatedArrivalTimeByCustomerAt.IsZero() {
+		t.expectedAt = data.LastUpdatedArrivalTimeByCustomerAt
+	}
+
+	t.readyAt = t.expectedAt
+	if t.customerCheckInAt.After(t.readyAt) {
+		t.readyAt = t.customerCheckInAt
+	}
+
+	return t, nil
+}
+
+// driverLateness returns minutes the driver arrived after the expected time.
+func (t *shuttleTimeline) driverLateness() float64 {
+	return nonNegativeMinutes(t.driverArriveAt.Sub(t.expectedAt))
+}
+
+// customerWait returns minutes the customer waited for the driver since being ready.
+func (t *shuttleTimeline) customerWait() float64 {
+	return nonNegativeMinutes(t.driverArriveAt.Sub(t.readyAt))
+}
+
+// driverWait returns minutes the driver waited from the later of the driver arrival and
+// the expected time until notifying the customer did not show up.
+func (t *shuttleTimeline) driverWait() float64 {
+	start := t.driverArriveAt
+	if t.expectedAt.After(start) {
+		start = t.expectedAt
+	}
+
+	return nonNegativeMinutes(t.noShowNotifiedAt.Sub(start))
+}
+
+// customerShowedUp returns true when the customer checked in before the driver notified a no-show.
+func (t *shuttleTimeline) customerShowedUp() bool {
+	return !t.customerCheckInAt.IsZero() && !t.customerCheckInAt.After(t.noShowNotifiedAt)
+}
+
+// nonNegativeMinutes returns the duration in minutes, negative durations count as zero.
+func nonNegativeMinutes(d time.Duration) float64 {
+	if d < 0 {
+		return 0
+	}
+
+	return d.Minutes()
+}
+
+// VerifyAirportShuttleAgreement verify airport shuttle agreement. The pickup timeline decides
+// whether the driver or the guest is at fault when the service did not go as agreed.
+func (s *EvaluationsContract) VerifyAirportShuttleAgreement(_ contractapi.TransactionContextInterface, a *Agreement, data *EvaluationData) (*EvaluationResult, error) {
+	p, ok := payloadOf(AgreementCategoryService, data).(*airportShuttlePayload)
+	if !ok {
+		return nil, fmt.Errorf("evaluation data code %s is not airport shuttle item code", data.Code)
+	}
+
+	return verifyAirportShuttleAgreement(a, p)
+}
+
+// verifyAirportShuttleAgreement verifies airport shuttle agreement against the airport shuttle payload.
+func verifyAirportShuttleAgreement(a *Agreement, data *airportShuttlePayload) (*EvaluationResult, error) {
+	agreementItem := a.Items[0]
+	switch data.Status {
+	case AirportShuttleStatusConfirmed, AirportShuttleStatusDriverWaiting, AirportShuttleStatusInService:
+		return nil, fmt.Errorf("can not verify airport shuttle agreement for status %s, the service has not finished yet", data.Status)
+
+	// customer cancels service
+	case AirportShuttleStatusCanceled:
+		return &EvaluationResult{
+			Satisfied: true,
+			Fault:     ShuttleFaultGuest,
+		}, nil
+
+	// driver doesn't show up
+	case AirportShuttleStatusNotServed:
+		return &EvaluationResult{
+			Satisfied:     false,
+			PenaltyRules:  defaultPenaltyRules(a, 0),
+			FailureReason: "driver did not come to pick up the passenger",
+			Severity:      PenaltySeverityCritical,
+			Fault:         ShuttleFaultDriver,
+		}, nil
+
+	// driver reports customer doesn't show up
+	case AirportShuttleStatusWaitingTimeExceeded:
+		timeline, err := newShuttleTimeline(data)
+		if err != nil {
+			return nil, err
+		}
+		if timeline.driverArriveAt.IsZero() || timeline.noShowNotifiedAt.IsZero() {
+			return nil, fmt.Errorf("driver arrival and no-show notification are required for status %s", data.Status)
+		}
+
+		switch {
+		case timeline.customerShowedUp():
+			return &EvaluationResult{
+				Satisfied:     false,
+				PenaltyRules:  defaultPenaltyRules(a, 0),
+				FailureReason: "the driver reported a no-show although the passenger checked in",
+				Severity:      PenaltySeverityCritical,
+				Fault:         ShuttleFaultDriver,
+			}, nil
+		case timeline.driverLateness() > float64(agreementItem.CustomerShortWaitTime):
+			return lateShuttleResult(a, timeline.driverLateness()), nil
+		case timeline.driverWait() < float64(agreementItem.DriverMaxWaitTime):
+			return &EvaluationResult{
+				Satisfied:     false,
+				PenaltyRules:  defaultPenaltyRules(a, 0),
+				FailureReason: "the driver did not wait for the passenger as agreed",
+				Severity:      PenaltySeverityMajor,
+				Fault:         ShuttleFaultDriver,
+			}, nil
+		default:
+			return &EvaluationResult{
+				Satisfied: true,
+				Fault:     ShuttleFaultGuest,
+			}, nil
+		}
+
+	case AirportShuttleStatusCompleted:
+		timeline, err := newShuttleTimeline(data)
+		if err != nil {
+			return nil, err
+		}
+		if timeline.driverArriveAt.IsZero() {
+			return nil, fmt.Errorf("driver arrival is required for status %s", data.Status)
+		}
+
+		if timeline.customerWait() <= float64(agreementItem.CustomerShortWaitTime) {
+			return &EvaluationResult{
+				Satisfied: true,
+			}, nil
+		}
+
+		return lateShuttleResult(a, timeline.customerWait()), nil
+
+	default:
+		return nil, fmt.Errorf("airport shuttle status %s has not supported yet", data.Status)
+	}
+}
+
+// lateShuttleResult returns the result of a driver who came late by the given minutes.
+func lateShuttleResult(a *Agreement, delay float64) *EvaluationResult {
+	agreementItem := a.Items[0]
+	eResult := &EvaluationResult{
+		Satisfied:     false,
+		PenaltyRules:  defaultPenaltyRules(a, 2),
+		FailureReason: "the driver came to pick up the passenger late",
+		Severity:      PenaltySeverityMajor,
+		Fault:         ShuttleFaultDriver,
+		DelayMinutes:  delay,
+		Score:         delayScore(delay, agreementItem.CustomerShortWaitTime, agreementItem.CustomerLongWaitTime),
+	}
+	if delay <= float64(agreementItem.CustomerLongWaitTime) {
+		eResult.PenaltyRules = defaultPenaltyRules(a, 1)
+		eResult.Severity = PenaltySeverityMinor
+	}
+
+	return eResult
+}
@@ -0,0 +1,226 @@
+package smartcontract
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerifyAirportShuttleAgreement(t *testing.T) {
+	pickUp := time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time {
+		return pickUp.Add(time.Duration(minutes) * time.Minute)
+	}
+
+	agreement := &Agreement{
+		Category: AgreementCategoryService,
+		Items: []*AgreementItem{{
+			Code:                  AgreementItemCodeServiceAirportShuttle,
+			DriverMaxWaitTime:     30,
+			CustomerShortWaitTime: 5,
+			CustomerLongWaitTime:  15,
+		}},
+		HasPenaltyRule: true,
+		PenaltyRules: []*PenaltyRule{
+			{Type: PenaltyRuleTypeDiscount, DiscountPercent: 100},
+			{Type: PenaltyRuleTypeDiscount, DiscountPercent: 20},
+			{Type: PenaltyRuleTypeDiscount, DiscountPercent: 50},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		data      *EvaluationData
+		wantErr   bool
+		satisfied bool
+		severity  string
+		fault     string
+		delay     float64
+		discount  float32
+	}{
+		{
+			name:    "confirmed has not finished",
+			data:    &EvaluationData{Status: AirportShuttleStatusConfirmed, PickUpTime: pickUp},
+			wantErr: true,
+		},
+		{
+			name:    "driver waiting has not finished",
+			data:    &EvaluationData{Status: AirportShuttleStatusDriverWaiting, PickUpTime: pickUp, DriverArriveAt: at(0)},
+			wantErr: true,
+		},
+		{
+			name:    "in service has not finished",
+			data:    &EvaluationData{Status: AirportShuttleStatusInService, PickUpTime: pickUp, DriverArriveAt: at(0)},
+			wantErr: true,
+		},
+		{
+			name:      "canceled by guest",
+			data:      &EvaluationData{Status: AirportShuttleStatusCanceled},
+			satisfied: true,
+			fault:     ShuttleFaultGuest,
+		},
+		{
+			name:     "not served",
+			data:     &EvaluationData{Status: AirportShuttleStatusNotServed, PickUpTime: pickUp},
+			severity: PenaltySeverityCritical,
+			fault:    ShuttleFaultDriver,
+			discount: 100,
+		},
+		{
+			name:      "completed on time",
+			data:      &EvaluationData{Status: AirportShuttleStatusCompleted, PickUpTime: pickUp, DriverArriveAt: at(5)},
+			satisfied: true,
+		},
+		{
+			name:     "completed late within long wait time",
+			data:     &EvaluationData{Status: AirportShuttleStatusCompleted, PickUpTime: pickUp, DriverArriveAt: at(10)},
+			severity: PenaltySeverityMinor,
+			fault:    ShuttleFaultDriver,
+			delay:    10,
+			discount: 20,
+		},
+		{
+			name:     "completed late beyond long wait time",
+			data:     &EvaluationData{Status: AirportShuttleStatusCompleted, PickUpTime: pickUp, DriverArriveAt: at(20)},
+			severity: PenaltySeverityMajor,
+			fault:    ShuttleFaultDriver,
+			delay:    20,
+			discount: 50,
+		},
+		{
+			name: "completed on time for arrival updated by customer",
+			data: &EvaluationData{
+				Status:                             AirportShuttleStatusCompleted,
+				PickUpTime:                         pickUp,
+				LastUpdatedArrivalTimeByCustomerAt: at(30),
+				DriverArriveAt:                     at(32),
+			},
+			satisfied: true,
+		},
+		{
+			name: "completed late for arrival updated by customer",
+			data: &EvaluationData{
+				Status:                             AirportShuttleStatusCompleted,
+				PickUpTime:                         pickUp,
+				LastUpdatedArrivalTimeByCustomerAt: at(30),
+				DriverArriveAt:                     at(40),
+			},
+			severity: PenaltySeverityMinor,
+			fault:    ShuttleFaultDriver,
+			delay:    10,
+			discount: 20,
+		},
+		{
+			name: "completed late by customer check-in",
+			data: &EvaluationData{
+				Status:            AirportShuttleStatusCompleted,
+				PickUpTime:        pickUp,
+				CustomerCheckInAt: at(20),
+				DriverArriveAt:    at(22),
+			},
+			satisfied: true,
+		},
+		{
+			name:    "completed without driver arrival",
+			data:    &EvaluationData{Status: AirportShuttleStatusCompleted, PickUpTime: pickUp},
+			wantErr: true,
+		},
+		{
+			name: "waiting time exceeded by guest",
+			data: &EvaluationData{
+				Status:                            AirportShuttleStatusWaitingTimeExceeded,
+				PickUpTime:                        pickUp,
+				DriverArriveAt:                    at(0),
+				DriverNotifyCustomerDoNotShowUpAt: at(31),
+			},
+			satisfied: true,
+			fault:     ShuttleFaultGuest,
+		},
+		{
+			name: "waiting time exceeded for arrival updated by customer",
+			data: &EvaluationData{
+				Status:                             AirportShuttleStatusWaitingTimeExceeded,
+				PickUpTime:                         pickUp,
+				LastUpdatedArrivalTimeByCustomerAt: at(60),
+				DriverArriveAt:                     at(0),
+				DriverNotifyCustomerDoNotShowUpAt:  at(70),
+			},
+			severity: PenaltySeverityMajor,
+			fault:    ShuttleFaultDriver,
+			discount: 100,
+		},
+		{
+			name: "waiting time exceeded although guest checked in",
+			data: &EvaluationData{
+				Status:                            AirportShuttleStatusWaitingTimeExceeded,
+				PickUpTime:                        pickUp,
+				CustomerCheckInAt:                 at(10),
+				DriverArriveAt:                    at(0),
+				DriverNotifyCustomerDoNotShowUpAt: at(31),
+			},
+			severity: PenaltySeverityCritical,
+			fault:    ShuttleFaultDriver,
+			discount: 100,
+		},
+		{
+			name: "waiting time exceeded by late driver",
+			data: &EvaluationData{
+				Status:                            AirportShuttleStatusWaitingTimeExceeded,
+				PickUpTime:                        pickUp,
+				DriverArriveAt:                    at(20),
+				DriverNotifyCustomerDoNotShowUpAt: at(60),
+			},
+			severity: PenaltySeverityMajor,
+			fault:    ShuttleFaultDriver,
+			delay:    20,
+			discount: 50,
+		},
+		{
+			name:    "waiting time exceeded without notification",
+			data:    &EvaluationData{Status: AirportShuttleStatusWaitingTimeExceeded, PickUpTime: pickUp, DriverArriveAt: at(0)},
+			wantErr: true,
+		},
+		{
+			name:    "unknown status",
+			data:    &EvaluationData{Status: "lost", PickUpTime: pickUp},
+			wantErr: true,
+		},
+	}
+
+	s := &EvaluationsContract{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.data.Code = AgreementItemCodeServiceAirportShuttle
+			eResult, err := s.VerifyAirportShuttleAgreement(nil, agreement, tt.data)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", eResult)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if eResult.Satisfied != tt.satisfied {
+				t.Errorf("satisfied = %v, want %v", eResult.Satisfied, tt.satisfied)
+			}
+			if eResult.Severity != tt.severity {
+				t.Errorf("severity = %q, want %q", eResult.Severity, tt.severity)
+			}
+			if eResult.Fault != tt.fault {
+				t.Errorf("fault = %q, want %q", eResult.Fault, tt.fault)
+			}
+			if eResult.DelayMinutes != tt.delay {
+				t.Errorf("delay minutes = %v, want %v", eResult.DelayMinutes, tt.delay)
+			}
+
+			var discount float32
+			for _, rule := range eResult.PenaltyRules {
+				discount += rule.DiscountPercent
+			}
+			if discount != tt.discount {
+				t.Errorf("discount percent = %v, want %v", discount, tt.discount)
+			}
+		})
+	}
+}
@@ -16,6 +16,8 @@ type Service struct {
 	NumberOfEvaluations uint64       `json:"numberOfEvaluations"`
 	LastEvaluationAt    string       `json:"lastEvaluationAt"`
 	Agreements          []*Agreement `json:"agreements"`
+	ViewRanking         []string     `json:"viewRanking,omitempty" metadata:"viewRanking,optional"`
+	SchemaVersion       int          `json:"schemaVersion,omitempty" metadata:"schemaVersion,optional"`
 }
 
 // Agreement stores information of a agreement of a service.
@@ -24,6 +26,9 @@ type Agreement struct {
 	Category    string           `json:"category"`
 	Items       []*AgreementItem `json:"items"`
 
+	// Version is increased whenever the terms of the agreement change.
+	Version int `json:"version"`
+
 	TotalFeedbacks                         uint `json:"totalFeedbacks"`
 	TotalUnsatisfied                       uint `json:"totalUnsatisfied"`
 	TotalRuleViolations                    uint `json:"totalRuleViolations"`
@@ -32,36 +37,117 @@ type Agreement struct {
 	HasPenaltyRule bool           `json:"hasPenaltyRule"`
 	PenaltyRules   []*PenaltyRule `json:"penaltyRules"`
 
+	PenaltyCombination string  `json:"penaltyCombination,omitempty" metadata:"penaltyCombination,optional"`
+	MaxDiscountPercent float32 `json:"maxDiscountPercent,omitempty" metadata:"maxDiscountPercent,optional"`
+	MaxAmount          float32 `json:"maxAmount,omitempty" metadata:"maxAmount,optional"`
+
+	ItemPolicy        string  `json:"itemPolicy,omitempty" metadata:"itemPolicy,optional"`
+	ItemPassThreshold float32 `json:"itemPassThreshold,omitempty" metadata:"itemPassThreshold,optional"`
+
+	BedMatchMode string `json:"bedMatchMode,omitempty" metadata:"bedMatchMode,optional"`
+
 	LastEvaluationAt string `json:"lastEvaluationAt"`
 
 	RuleAbidingRate  float32 `json:"ruleAbidingRate"`
 	SatisfactionRate float32 `json:"satisfactionRate"`
+
+	SatisfactionRateStrategy string  `json:"satisfactionRateStrategy,omitempty" metadata:"satisfactionRateStrategy,optional"`
+	TotalScore               float64 `json:"totalScore"`
+	ScoredFeedbacks          uint    `json:"scoredFeedbacks"`
+	AverageScore             float32 `json:"averageScore"`
+
+	RecentEvaluations []*RecentEvaluation `json:"recentEvaluations,omitempty" metadata:"recentEvaluations,optional"`
+}
+
+// AgreementPolicy stores how a agreement is verified and rated. The item policy, item pass threshold and
+// satisfaction rate strategy of a agreement take precedence over the ones of the contract configuration,
+// which only apply when the agreement leaves them empty.
+type AgreementPolicy struct {
+	PenaltyCombination string  `json:"penaltyCombination,omitempty" metadata:"penaltyCombination,optional"`
+	MaxDiscountPercent float32 `json:"maxDiscountPercent,omitempty" metadata:"maxDiscountPercent,optional"`
+	MaxAmount          float32 `json:"maxAmount,omitempty" metadata:"maxAmount,optional"`
+
+	ItemPolicy        string  `json:"itemPolicy,omitempty" metadata:"itemPolicy,optional"`
+	ItemPassThreshold float32 `json:"itemPassThreshold,omitempty" metadata:"itemPassThreshold,optional"`
+
+	BedMatchMode string `json:"bedMatchMode,omitempty" metadata:"bedMatchMode,optional"`
+
+	SatisfactionRateStrategy string `json:"satisfactionRateStrategy,omitempty" metadata:"satisfactionRateStrategy,optional"`
 }
 
 // AgreementItem an item in a agreement.
 type AgreementItem struct {
-	Code string `json:"code"`
+	Code   string  `json:"code"`
+	Weight float64 `json:"weight,omitempty" metadata:"weight,optional"`
 
 	// facilities
 	Quantity int `json:"quantity,omitempty" metadata:"quantity,optional"`
 
+	// view
+	ViewMode         string   `json:"viewMode,omitempty" metadata:"viewMode,optional"`
+	AcceptableViews  []string `json:"acceptableViews,omitempty" metadata:"acceptableViews,optional"`
+	AllowPartialView bool     `json:"allowPartialView,omitempty" metadata:"allowPartialView,optional"`
+
 	// airport shuttle
 	DriverMaxWaitTime     int `json:"driverMaxWaitTime,omitempty" metadata:"driverMaxWaitTime,optional"`
 	CustomerShortWaitTime int `json:"customerShortWaitTime,omitempty" metadata:"customerShortWaitTime,optional"`
 	CustomerLongWaitTime  int `json:"customerLongWaitTime,omitempty" metadata:"customerLongWaitTime,optional"`
 
 	// sauna
-	MaxFailures             int `json:"maxFailures,omitempty" metadata:"maxFailures,optional"`
-	MinTimeBetween2Failures int `json:"minTimeBetween2Failures,omitempty" metadata:"minTimeBetween2Failures,optional"`
+	SaunaMode               string  `json:"saunaMode,omitempty" metadata:"saunaMode,optional"`
+	MaxFailures             int     `json:"maxFailures,omitempty" metadata:"maxFailures,optional"`
+	MinTimeBetween2Failures int     `json:"minTimeBetween2Failures,omitempty" metadata:"minTimeBetween2Failures,optional"`
+	MinSuccessRatio         float64 `json:"minSuccessRatio,omitempty" metadata:"minSuccessRatio,optional"`
+
+	// breakfast, clock times in HH:MM
+	BreakfastOpenTime     string `json:"breakfastOpenTime,omitempty" metadata:"breakfastOpenTime,optional"`
+	BreakfastCloseTime    string `json:"breakfastCloseTime,omitempty" metadata:"breakfastCloseTime,optional"`
+	BreakfastGraceMinutes int    `json:"breakfastGraceMinutes,omitempty" metadata:"breakfastGraceMinutes,optional"`
+
+	// housekeeping, deadline clock time in HH:MM
+	HousekeepingDeadline string `json:"housekeepingDeadline,omitempty" metadata:"housekeepingDeadline,optional"`
+
+	// breakfast and housekeeping, number of missed days tolerated
+	MaxMissedDays int `json:"maxMissedDays,omitempty" metadata:"maxMissedDays,optional"`
+
+	// wifi
+	MinBandwidthMbps float64 `json:"minBandwidthMbps,omitempty" metadata:"minBandwidthMbps,optional"`
+	MinUptimePercent float64 `json:"minUptimePercent,omitempty" metadata:"minUptimePercent,optional"`
+
+	// check-in
+	CheckInShortWaitTime int `json:"checkInShortWaitTime,omitempty" metadata:"checkInShortWaitTime,optional"`
+	CheckInLongWaitTime  int `json:"checkInLongWaitTime,omitempty" metadata:"checkInLongWaitTime,optional"`
 
 	// room design
-	Value interface{} `json:"value,omitempty" metadata:"value,optional"`
+	Value            interface{} `json:"value,omitempty" metadata:"value,optional"`
+	Unit             string      `json:"unit,omitempty" metadata:"unit,optional"`
+	TolerancePercent float64     `json:"tolerancePercent,omitempty" metadata:"tolerancePercent,optional"`
+
+	// booked room category, used as the base of room category upgrades
+	RoomCategory string `json:"roomCategory,omitempty" metadata:"roomCategory,optional"`
+
+	// custom term
+	Expression string                 `json:"expression,omitempty" metadata:"expression,optional"`
+	Parameters map[string]interface{} `json:"parameters,omitempty" metadata:"parameters,optional"`
 }
 
 // PenaltyRule types of penalty rule.
 type PenaltyRule struct {
 	Type            string  `json:"type"`
 	DiscountPercent float32 `json:"discountPercent,omitempty" metadata:"discountPercent,optional"`
+	Amount          float32 `json:"amount,omitempty" metadata:"amount,optional"`
+	DueHours        int     `json:"dueHours,omitempty" metadata:"dueHours,optional"`
+
+	// upgrade level
+	UpgradeDimension string `json:"upgradeDimension,omitempty" metadata:"upgradeDimension,optional"`
+	UpgradeLevels    int    `json:"upgradeLevels,omitempty" metadata:"upgradeLevels,optional"`
+
+	// trigger condition, a rule without condition is triggered by any unsatisfied evaluation
+	Severity          string  `json:"severity,omitempty" metadata:"severity,optional"`
+	MinDelayMinutes   float64 `json:"minDelayMinutes,omitempty" metadata:"minDelayMinutes,optional"`
+	MaxDelayMinutes   float64 `json:"maxDelayMinutes,omitempty" metadata:"maxDelayMinutes,optional"`
+	MinFailures       int     `json:"minFailures,omitempty" metadata:"minFailures,optional"`
+	MinRepeatOffenses uint    `json:"minRepeatOffenses,omitempty" metadata:"minRepeatOffenses,optional"`
 }
 
 // EvaluationData data for verifying agreement.
@@ -72,6 +158,9 @@ type EvaluationData struct {
 	// facility attributes
 	Quantity int `json:"quantity,omitempty" metadata:"quantity,optional"`
 
+	// view attributes
+	Partial bool `json:"partial,omitempty" metadata:"partial,optional"`
+
 	// airport shuttle service attributes
 	PickUpTime                         time.Time `json:"pickUpTime,omitempty" metadata:"pickUpTime,optional"`
 	DriverArriveAt                     time.Time `json:"driverArriveAt,omitempty" metadata:"driverArriveAt,optional"`
@@ -83,11 +172,33 @@ type EvaluationData struct {
 	// sauna attributes
 	SaunaRequests []*SaunaRequest `json:"saunaRequests,omitempty" metadata:"saunaRequests,optional"`
 
+	// stay attributes of breakfast and housekeeping
+	StayStartDate string `json:"stayStartDate,omitempty" metadata:"stayStartDate,optional"`
+	StayDays      int    `json:"stayDays,omitempty" metadata:"stayDays,optional"`
+
+	// breakfast attributes
+	BreakfastDays []*BreakfastDay `json:"breakfastDays,omitempty" metadata:"breakfastDays,optional"`
+
+	// housekeeping attributes
+	HousekeepingCompletedAt []time.Time `json:"housekeepingCompletedAt,omitempty" metadata:"housekeepingCompletedAt,optional"`
+
+	// wifi attributes
+	BandwidthSamplesMbps []float64 `json:"bandwidthSamplesMbps,omitempty" metadata:"bandwidthSamplesMbps,optional"`
+	UptimePercent        float64   `json:"uptimePercent,omitempty" metadata:"uptimePercent,optional"`
+
+	// check-in attributes
+	ArriveAtFrontDeskAt time.Time `json:"arriveAtFrontDeskAt,omitempty" metadata:"arriveAtFrontDeskAt,optional"`
+	CheckInServedAt     time.Time `json:"checkInServedAt,omitempty" metadata:"checkInServedAt,optional"`
+
 	// service attributes
 	Fulfilled string `json:"fulfilled,omitempty" metadata:"fulfilled,optional"`
 
 	// room design and other attributes
 	Value interface{} `json:"value,omitempty" metadata:"value,optional"`
+	Unit  string      `json:"unit,omitempty" metadata:"unit,optional"`
+
+	// custom term attributes
+	Metrics map[string]interface{} `json:"metrics,omitempty" metadata:"metrics,optional"`
 }
 
 // SaunaRequest represents sauna request.
@@ -96,25 +207,243 @@ type SaunaRequest struct {
 	Status    string    `json:"status"`
 }
 
+// BreakfastDay represents the opening of breakfast on a day of the stay.
+// A day without opening time is a day breakfast was not served.
+type BreakfastDay struct {
+	Date    string    `json:"date"`
+	OpenAt  time.Time `json:"openAt,omitempty" metadata:"openAt,optional"`
+	CloseAt time.Time `json:"closeAt,omitempty" metadata:"closeAt,optional"`
+}
+
 // Evaluation stores information for auditing.
 type Evaluation struct {
+	DocType      string  `json:"docType"` // docType is used to distinguish the various types of objects in state database.
+	EvaluationID string  `json:"evaluationId"`
+	ServiceID    string  `json:"serviceId"`
+	AgreementID  string  `json:"agreementId"`
+	TxID         string  `json:"txId"`
+	Hash         string  `json:"hash"`
+	Verdict      string  `json:"verdict,omitempty" metadata:"verdict,optional"`
+	Score        float32 `json:"score"`
+	Scored       bool    `json:"scored"` // evaluations recorded before scoring was introduced have no score
+
+	Type             string `json:"type,omitempty" metadata:"type,optional"`
+	AgreementVersion int    `json:"agreementVersion,omitempty" metadata:"agreementVersion,optional"`
+	EvaluatedAt      string `json:"evaluatedAt,omitempty" metadata:"evaluatedAt,optional"`
+
+	// triggered penalty
+	PenaltyRules    []*PenaltyRule `json:"penaltyRules,omitempty" metadata:"penaltyRules,optional"`
+	DiscountPercent float32        `json:"discountPercent,omitempty" metadata:"discountPercent,optional"`
+	Amount          float32        `json:"amount,omitempty" metadata:"amount,optional"`
+	UpgradeTarget   string         `json:"upgradeTarget,omitempty" metadata:"upgradeTarget,optional"`
+	Severity        string         `json:"severity,omitempty" metadata:"severity,optional"`
+	FailureReason   string         `json:"failureReason,omitempty" metadata:"failureReason,optional"`
+}
+
+// EvaluationResult represents for a evaluation result.
+type EvaluationResult struct {
+	Satisfied     bool           `json:"satisfied"`
+	Score         float32        `json:"score"`
+	PenaltyRules  []*PenaltyRule `json:"penaltyRules,omitempty" metadata:"penaltyRules,optional"`
+	FailureReason string         `json:"failureReason,omitempty" metadata:"failureReason,optional"`
+
+	// PenaltyRule is the first triggered penalty rule, it is kept for clients of the single rule format.
+	PenaltyRule *PenaltyRule `json:"penaltyRule,omitempty" metadata:"penaltyRule,optional"`
+
+	Items []*ItemEvaluationResult `json:"items,omitempty" metadata:"items,optional"`
+
+	// facts used to select penalty rules
+	Severity       string  `json:"severity,omitempty" metadata:"severity,optional"`
+	DelayMinutes   float64 `json:"delayMinutes,omitempty" metadata:"delayMinutes,optional"`
+	Failures       int     `json:"failures,omitempty" metadata:"failures,optional"`
+	RepeatOffenses uint    `json:"repeatOffenses,omitempty" metadata:"repeatOffenses,optional"`
+
+	// party at fault, driver or guest for airport shuttles
+	Fault string `json:"fault,omitempty" metadata:"fault,optional"`
+
+	// compensation combined from the triggered penalty rules
+	DiscountPercent float32 `json:"discountPercent,omitempty" metadata:"discountPercent,optional"`
+	Amount          float32 `json:"amount,omitempty" metadata:"amount,optional"`
+	UpgradeTarget   string  `json:"upgradeTarget,omitempty" metadata:"upgradeTarget,optional"`
+}
+
+// ItemEvaluationResult represents for a evaluation result of a agreement item.
+type ItemEvaluationResult struct {
+	Code          string  `json:"code"`
+	Satisfied     bool    `json:"satisfied"`
+	Score         float32 `json:"score"`
+	Severity      string  `json:"severity,omitempty" metadata:"severity,optional"`
+	Fault         string  `json:"fault,omitempty" metadata:"fault,optional"`
+	FailureReason string  `json:"failureReason,omitempty" metadata:"failureReason,optional"`
+}
+
+// Compensation stores what a provider owes for triggered penalty rules
+// and how it has been fulfilled.
+type Compensation struct {
+	DocType        string         `json:"docType"` // docType is used to distinguish the various types of objects in state database.
+	CompensationID string         `json:"compensationId"`
+	ServiceID      string         `json:"serviceId"`
+	AgreementID    string         `json:"agreementId"`
+	EvaluationID   string         `json:"evaluationId"`
+	ReservationID  string         `json:"reservationId,omitempty" metadata:"reservationId,optional"`
+	PenaltyRules   []*PenaltyRule `json:"penaltyRules"`
+
+	// PenaltyRule is the first triggered penalty rule, it is kept for clients of the single rule format.
+	PenaltyRule *PenaltyRule `json:"penaltyRule,omitempty" metadata:"penaltyRule,optional"`
+
+	Amount          float32 `json:"amount,omitempty" metadata:"amount,optional"`
+	DiscountPercent float32 `json:"discountPercent,omitempty" metadata:"discountPercent,optional"`
+	UpgradeTarget   string  `json:"upgradeTarget,omitempty" metadata:"upgradeTarget,optional"`
+
+	Status       string `json:"status"`
+	Overdue      bool   `json:"overdue"`
+	CreatedAt    string `json:"createdAt"`
+	DueAt        string `json:"dueAt"`
+	SettledAt    string `json:"settledAt,omitempty" metadata:"settledAt,optional"`
+	EvidenceHash string `json:"evidenceHash,omitempty" metadata:"evidenceHash,optional"`
+	RefundedAt   string `json:"refundedAt,omitempty" metadata:"refundedAt,optional"`
+	TxID         string `json:"txId"`
+}
+
+// Dispute stores a provider's objection to an evaluation outcome.
+type Dispute struct {
 	DocType      string `json:"docType"` // docType is used to distinguish the various types of objects in state database.
+	DisputeID    string `json:"disputeId"`
 	EvaluationID string `json:"evaluationId"`
 	ServiceID    string `json:"serviceId"`
 	AgreementID  string `json:"agreementId"`
+	Status       string `json:"status"`
+	Reason       string `json:"reason,omitempty" metadata:"reason,optional"`
+	EvidenceHash string `json:"evidenceHash"`
+	OpenedBy     string `json:"openedBy"`
+	OpenedAt     string `json:"openedAt"`
+	Resolution   string `json:"resolution,omitempty" metadata:"resolution,optional"`
+	ResolvedBy   string `json:"resolvedBy,omitempty" metadata:"resolvedBy,optional"`
+	ResolvedAt   string `json:"resolvedAt,omitempty" metadata:"resolvedAt,optional"`
 	TxID         string `json:"txId"`
-	Hash         string `json:"hash"`
 }
 
-// EvaluationResult represents for a evaluation result.
-type EvaluationResult struct {
-	Satisfied     bool         `json:"satisfied"`
-	PenaltyRule   *PenaltyRule `json:"penaltyRule,omitempty" metadata:"penaltyRule,optional"`
-	FailureReason string       `json:"failureReason,omitempty" metadata:"failureReason,optional"`
+// DisputeHistory represents a version of a dispute in the ledger history.
+type DisputeHistory struct {
+	TxID      string   `json:"txId"`
+	Timestamp string   `json:"timestamp"`
+	IsDelete  bool     `json:"isDelete"`
+	Dispute   *Dispute `json:"dispute,omitempty" metadata:"dispute,optional"`
+}
+
+// EvaluationSubmission is a satisfaction evaluation submitted in a batch.
+type EvaluationSubmission struct {
+	ServiceID          string `json:"serviceId"`
+	AgreementID        string `json:"agreementId"`
+	EvaluationID       string `json:"evaluationId"`
+	ReservationID      string `json:"reservationId"`
+	Hash               string `json:"hash"`
+	At                 string `json:"at"`
+	Satisfied          bool   `json:"satisfied"`
+	EnforcePenaltyRule bool   `json:"enforcePenaltyRule"`
+}
+
+// EvaluationSubmissionResult represents for the result of a submission in a batch.
+type EvaluationSubmissionResult struct {
+	EvaluationID string `json:"evaluationId"`
+	ServiceID    string `json:"serviceId"`
+	AgreementID  string `json:"agreementId"`
+	Recorded     bool   `json:"recorded"`
+	Error        string `json:"error,omitempty" metadata:"error,optional"`
+}
+
+// CatalogueCategory stores a agreement category of the catalogue.
+type CatalogueCategory struct {
+	DocType  string `json:"docType"`
+	Category string `json:"category"`
+	Name     string `json:"name"`
+}
+
+// CatalogueItem stores a agreement item code of the catalogue.
+type CatalogueItem struct {
+	DocType  string `json:"docType"`
+	Code     string `json:"code"`
+	Category string `json:"category"`
+	Name     string `json:"name"`
+
+	// JSON schema of the parameters of agreement items with this code
+	ParameterSchema string `json:"parameterSchema,omitempty" metadata:"parameterSchema,optional"`
+	// ranking value of the code in it's category, the level of a view or the points of a bed
+	Rank int `json:"rank"`
 }
 
-// AccessKey represents for a access key.
+// AccessKey represents for a access key, it is only stored in the private data of the platform org.
 type AccessKey struct {
-	Type  string `json:"type"`
-	Token string `json:"key"`
+	Type      string    `json:"type"`
+	Token     string    `json:"key"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// AccessKeyInfo represents for the metadata of a access key, the token is never returned.
+type AccessKeyInfo struct {
+	Type      string    `json:"type"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Expired   bool      `json:"expired"`
+}
+
+// ContractInfo represents for information of the deployed contract.
+type ContractInfo struct {
+	Version             string   `json:"version"`
+	SupportedCategories []string `json:"supportedCategories"`
+	ConfigHash          string   `json:"configHash"`
+}
+
+// ContractConfig stores the governed configuration of the chaincode.
+type ContractConfig struct {
+	DocType string `json:"docType"`
+
+	// PenaltyEndpoint is the url penalty rules are enforced at.
+	PenaltyEndpoint              string `json:"penaltyEndpoint"`
+	PenaltyRequestTimeoutSeconds int    `json:"penaltyRequestTimeoutSeconds"`
+
+	// RateStrategy, ItemPolicy and ItemPassThreshold apply to agreements which do not set their own,
+	// the agreement policy always wins over them.
+	RateStrategy      string  `json:"rateStrategy"`
+	ItemPolicy        string  `json:"itemPolicy"`
+	ItemPassThreshold float32 `json:"itemPassThreshold"`
+
+	// RateWindowSize is the number of latest evaluations the satisfaction rate is computed over,
+	// every evaluation counts when it is 0.
+	RateWindowSize int `json:"rateWindowSize"`
+
+	CompensationDueHours int `json:"compensationDueHours"`
+
+	PenaltyEnforcementEnabled bool `json:"penaltyEnforcementEnabled"`
+	CompensationsEnabled      bool `json:"compensationsEnabled"`
+
+	// PlatformMSPID is the MSP ID of the platform org, secrets are kept in it's implicit private data
+	// collection and penalties are only enforced from it's peers.
+	PlatformMSPID string `json:"platformMspId"`
+
+	Version   int    `json:"version"`
+	UpdatedBy string `json:"updatedBy,omitempty" metadata:"updatedBy,optional"`
+	UpdatedAt string `json:"updatedAt,omitempty" metadata:"updatedAt,optional"`
+	TxID      string `json:"txId,omitempty" metadata:"txId,optional"`
+}
+
+// RecentEvaluation represents for a evaluation counted in the rate window of a agreement.
+type RecentEvaluation struct {
+	EvaluationID string  `json:"evaluationId"`
+	Satisfied    bool    `json:"satisfied"`
+	Score        float32 `json:"score"`
+}
+
+// SchemaMigration represents for a migration of the stored services between schema versions.
+type SchemaMigration struct {
+	FromVersion      int    `json:"fromVersion"`
+	ToVersion        int    `json:"toVersion"`
+	MigratedServices int    `json:"migratedServices"`
+	TxID             string `json:"txId"`
+
+	// NextStartKey is the start key of the next page of services, it is empty when every service is migrated.
+	NextStartKey string `json:"nextStartKey,omitempty" metadata:"nextStartKey,optional"`
 }
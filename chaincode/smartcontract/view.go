@@ -0,0 +1,105 @@
+package smartcontract
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// validateViewItem validates the view mode and acceptable views of a view item.
+func validateViewItem(c *catalogue, item *AgreementItem) error {
+	switch item.ViewMode {
+	case "", ViewModeExact, ViewModeRankedUpgrade:
+		if len(item.AcceptableViews) > 0 {
+			return fmt.Errorf("only items in view mode %s can have acceptable views", ViewModeAnyOf)
+		}
+	case ViewModeAnyOf:
+		for _, code := range item.AcceptableViews {
+			if !c.hasItem(AgreementCategoryView, code) {
+				return MakeErrorAgreementItemCodeDoesNotSupport(code, AgreementCategoryView)
+			}
+		}
+	default:
+		return fmt.Errorf("view mode %s has not supported yet", item.ViewMode)
+	}
+
+	return nil
+}
+
+// viewLevels returns the level of each view in the ranking of a service, or the ranks of views in the catalogue.
+func viewLevels(c *catalogue, ranking []string) map[string]int {
+	if len(ranking) == 0 {
+		return c.ranks(AgreementCategoryView)
+	}
+
+	levels := make(map[string]int, len(ranking))
+	for i, code := range ranking {
+		levels[code] = i
+	}
+
+	return levels
+}
+
+// VerifyViewAgreement verify view agreement. A view of the room matches the agreed view by the
+// view mode of the agreement item:
+//   - exact: the view is the agreed view.
+//   - any_of: the view is the agreed view or one of the acceptable views.
+//   - ranked_upgrade: the view is ranked the same as or higher than the agreed view in the
+//     given view ranking of the service, or in the catalogue when the ranking is empty.
+//
+// Items without view mode are verified by ranked upgrade, as view agreements were before view modes.
+// A partial view only satisfies the agreement when the agreement allows partial views.
+func (s *EvaluationsContract) VerifyViewAgreement(ctx contractapi.TransactionContextInterface, a *Agreement, data []*EvaluationData, ranking []string) (*EvaluationResult, error) {
+	return verifyViewAgreement(ctx, a, viewPayloads(payloadsOf(AgreementCategoryView, data)), ranking)
+}
+
+// verifyViewAgreement verifies view agreement against the view payloads of the room.
+func verifyViewAgreement(ctx contractapi.TransactionContextInterface, a *Agreement, data []*viewPayload, ranking []string) (*EvaluationResult, error) {
+	c, err := loadCatalogue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	agreementItem := a.Items[0]
+	levels := viewLevels(c, ranking)
+
+	var score float32
+	for _, item := range data {
+		var matched bool
+		switch agreementItem.ViewMode {
+		case ViewModeExact:
+			matched = item.Code == agreementItem.Code
+		case ViewModeAnyOf:
+			matched = item.Code == agreementItem.Code || StringInSlice(item.Code, agreementItem.AcceptableViews)
+		case "", ViewModeRankedUpgrade:
+			level, ranked := levels[item.Code]
+			agreedLevel, agreedRanked := levels[agreementItem.Code]
+			matched = ranked && agreedRanked && level >= agreedLevel
+		default:
+			return nil, fmt.Errorf("view mode %s has not supported yet", agreementItem.ViewMode)
+		}
+		if !matched {
+			continue
+		}
+
+		if !item.Partial || agreementItem.AllowPartialView {
+			return &EvaluationResult{
+				Satisfied: true,
+				Score:     1,
+			}, nil
+		}
+		score = PartialViewScore
+	}
+
+	reason := fmt.Sprintf("the room does not have view %s", agreementItem.Code)
+	if score > 0 {
+		reason = fmt.Sprintf("the room has only a partial view %s", agreementItem.Code)
+	}
+
+	return &EvaluationResult{
+		Satisfied:     false,
+		PenaltyRules:  defaultPenaltyRules(a, 0),
+		FailureReason: reason,
+		Score:         score,
+	}, nil
+}
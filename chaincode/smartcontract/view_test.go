@@ -0,0 +1,175 @@
+package smartcontract
+
+import (
+	b64 "encoding/base64"
+	"testing"
+)
+
+func TestVerifyViewAgreement(t *testing.T) {
+	ctx, _ := newTestContext(t)
+	s := &EvaluationsContract{}
+
+	view := func(code string, partial bool) []*EvaluationData {
+		return []*EvaluationData{{Code: code, Partial: partial}}
+	}
+	// the hotel ranks its garden above the sea
+	ranking := []string{AgreementItemCodeViewCity, AgreementItemCodeViewSea, AgreementItemCodeViewGarden}
+
+	tests := []struct {
+		name      string
+		item      *AgreementItem
+		data      []*EvaluationData
+		ranking   []string
+		wantErr   bool
+		satisfied bool
+		score     float32
+	}{
+		{
+			name:      "exact view",
+			item:      &AgreementItem{Code: AgreementItemCodeViewSea, ViewMode: ViewModeExact},
+			data:      view(AgreementItemCodeViewSea, false),
+			satisfied: true,
+			score:     1,
+		},
+		{
+			name: "exact other view",
+			item: &AgreementItem{Code: AgreementItemCodeViewRiver, ViewMode: ViewModeExact},
+			data: view(AgreementItemCodeViewSea, false),
+		},
+		{
+			name:      "any of acceptable views",
+			item:      &AgreementItem{Code: AgreementItemCodeViewSea, ViewMode: ViewModeAnyOf, AcceptableViews: []string{AgreementItemCodeViewRiver}},
+			data:      view(AgreementItemCodeViewRiver, false),
+			satisfied: true,
+			score:     1,
+		},
+		{
+			name: "any of other view",
+			item: &AgreementItem{Code: AgreementItemCodeViewSea, ViewMode: ViewModeAnyOf, AcceptableViews: []string{AgreementItemCodeViewRiver}},
+			data: view(AgreementItemCodeViewPool, false),
+		},
+		{
+			name:      "ranked upgrade by the catalogue",
+			item:      &AgreementItem{Code: AgreementItemCodeViewGarden, ViewMode: ViewModeRankedUpgrade},
+			data:      view(AgreementItemCodeViewSea, false),
+			satisfied: true,
+			score:     1,
+		},
+		{
+			name: "ranked downgrade by the catalogue",
+			item: &AgreementItem{Code: AgreementItemCodeViewGarden, ViewMode: ViewModeRankedUpgrade},
+			data: view(AgreementItemCodeViewCity, false),
+		},
+		{
+			name:      "missing mode is ranked upgrade",
+			item:      &AgreementItem{Code: AgreementItemCodeViewMountain},
+			data:      view(AgreementItemCodeViewCity, false),
+			satisfied: true,
+			score:     1,
+		},
+		{
+			name:    "ranked downgrade by the service",
+			item:    &AgreementItem{Code: AgreementItemCodeViewGarden, ViewMode: ViewModeRankedUpgrade},
+			data:    view(AgreementItemCodeViewSea, false),
+			ranking: ranking,
+		},
+		{
+			name:      "ranked upgrade by the service",
+			item:      &AgreementItem{Code: AgreementItemCodeViewSea},
+			data:      view(AgreementItemCodeViewGarden, false),
+			ranking:   ranking,
+			satisfied: true,
+			score:     1,
+		},
+		{
+			name:    "view missing in the ranking of the service",
+			item:    &AgreementItem{Code: AgreementItemCodeViewCity},
+			data:    view(AgreementItemCodeViewRiver, false),
+			ranking: ranking,
+		},
+		{
+			name:  "partial view",
+			item:  &AgreementItem{Code: AgreementItemCodeViewSea, ViewMode: ViewModeExact},
+			data:  view(AgreementItemCodeViewSea, true),
+			score: PartialViewScore,
+		},
+		{
+			name:      "partial view allowed",
+			item:      &AgreementItem{Code: AgreementItemCodeViewSea, ViewMode: ViewModeExact, AllowPartialView: true},
+			data:      view(AgreementItemCodeViewSea, true),
+			satisfied: true,
+			score:     1,
+		},
+		{
+			name:      "full view after a partial one",
+			item:      &AgreementItem{Code: AgreementItemCodeViewSea, ViewMode: ViewModeExact},
+			data:      append(view(AgreementItemCodeViewSea, true), view(AgreementItemCodeViewSea, false)...),
+			satisfied: true,
+			score:     1,
+		},
+		{
+			name:    "unknown mode",
+			item:    &AgreementItem{Code: AgreementItemCodeViewSea, ViewMode: "closest"},
+			data:    view(AgreementItemCodeViewSea, false),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agreement := &Agreement{
+				Category:       AgreementCategoryView,
+				Items:          []*AgreementItem{tt.item},
+				HasPenaltyRule: true,
+				PenaltyRules:   []*PenaltyRule{{Type: PenaltyRuleTypeDiscount, DiscountPercent: 10}},
+			}
+			eResult, err := s.VerifyViewAgreement(ctx, agreement, tt.data, tt.ranking)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if eResult.Satisfied != tt.satisfied {
+				t.Errorf("satisfied = %v, want %v", eResult.Satisfied, tt.satisfied)
+			}
+			if !floatEqual(eResult.Score, tt.score) {
+				t.Errorf("score = %v, want %v", eResult.Score, tt.score)
+			}
+		})
+	}
+}
+
+func TestVerifyEvaluationDataUsesViewRankingOfService(t *testing.T) {
+	ctx, _ := newTestContext(t)
+	s := &EvaluationsContract{}
+
+	agreement := &Agreement{
+		AgreementID: "agreement1",
+		Category:    AgreementCategoryView,
+		Items:       []*AgreementItem{{Code: AgreementItemCodeViewSea}},
+	}
+	service := &Service{
+		ServiceID:   "service1",
+		Agreements:  []*Agreement{agreement},
+		ViewRanking: []string{AgreementItemCodeViewSea, AgreementItemCodeViewCity},
+	}
+	eData := b64.StdEncoding.EncodeToString([]byte(`[{"code":"` + AgreementItemCodeViewCity + `"}]`))
+
+	eResult, err := s.verifyEvaluationData(ctx, service, agreement, eData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !eResult.Satisfied {
+		t.Errorf("city view is not an upgrade of sea view in the ranking of the service")
+	}
+
+	eResult, err = s.VerifySLA(ctx, agreement, []*EvaluationData{{Code: AgreementItemCodeViewCity}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eResult.Satisfied {
+		t.Errorf("city view is an upgrade of sea view in the catalogue")
+	}
+}
@@ -0,0 +1,103 @@
+package smartcontract
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// validateWifiItem validates the bandwidth and uptime of a Wi-Fi item.
+func validateWifiItem(item *AgreementItem) error {
+	if item.MinBandwidthMbps < 0 {
+		return fmt.Errorf("min bandwidth of agreement item %s must not be negative", item.Code)
+	}
+	if item.MinUptimePercent < 0 || item.MinUptimePercent > 100 {
+		return fmt.Errorf("min uptime percent of agreement item %s must be between 0 and 100", item.Code)
+	}
+	if item.MinBandwidthMbps == 0 && item.MinUptimePercent == 0 {
+		return fmt.Errorf("agreement item %s must have a min bandwidth or a min uptime percent", item.Code)
+	}
+
+	return nil
+}
+
+// VerifyWifiAgreement verify Wi-Fi agreement. The median of the measured bandwidth samples must
+// reach the agreed bandwidth and the measured uptime must reach the agreed uptime.
+// A Wi-Fi which was down most of the agreed uptime is a critical failure.
+func (s *EvaluationsContract) VerifyWifiAgreement(_ contractapi.TransactionContextInterface, a *Agreement, data *EvaluationData) (*EvaluationResult, error) {
+	p, ok := payloadOf(AgreementCategoryService, data).(*wifiPayload)
+	if !ok {
+		return nil, fmt.Errorf("evaluation data code %s is not wifi service item code", data.Code)
+	}
+
+	return verifyWifiAgreement(a, p)
+}
+
+// verifyWifiAgreement verifies Wi-Fi agreement against the Wi-Fi payload.
+func verifyWifiAgreement(a *Agreement, data *wifiPayload) (*EvaluationResult, error) {
+	if data.UptimePercent < 0 || data.UptimePercent > 100 {
+		return nil, fmt.Errorf("uptime percent must be between 0 and 100")
+	}
+
+	agreementItem := a.Items[0]
+	var reasons []string
+	var score float32 = 1
+
+	if agreementItem.MinBandwidthMbps > 0 {
+		if len(data.BandwidthSamplesMbps) == 0 {
+			return nil, fmt.Errorf("bandwidth samples are required to verify agreement item %s", agreementItem.Code)
+		}
+
+		bandwidth := medianOf(data.BandwidthSamplesMbps)
+		if bandwidth < agreementItem.MinBandwidthMbps {
+			reasons = append(reasons, fmt.Sprintf("bandwidth was %.2f Mbps but %.2f Mbps was agreed", bandwidth, agreementItem.MinBandwidthMbps))
+		}
+		score = ratioScore(bandwidth, agreementItem.MinBandwidthMbps)
+	}
+
+	var uptimeScore float32 = 1
+	if agreementItem.MinUptimePercent > 0 {
+		if data.UptimePercent < agreementItem.MinUptimePercent {
+			reasons = append(reasons, fmt.Sprintf("uptime was %.2f%% but %.2f%% was agreed", data.UptimePercent, agreementItem.MinUptimePercent))
+		}
+		uptimeScore = ratioScore(data.UptimePercent, agreementItem.MinUptimePercent)
+		if uptimeScore < score {
+			score = uptimeScore
+		}
+	}
+
+	if len(reasons) == 0 {
+		return &EvaluationResult{
+			Satisfied: true,
+			Score:     score,
+		}, nil
+	}
+
+	eResult := &EvaluationResult{
+		Satisfied:     false,
+		PenaltyRules:  defaultPenaltyRules(a, 0),
+		FailureReason: strings.Join(reasons, "; "),
+		Score:         score,
+	}
+	if uptimeScore < 0.5 {
+		eResult.Severity = PenaltySeverityCritical
+	}
+
+	return eResult, nil
+}
+
+// medianOf returns the median of the values.
+func medianOf(values []float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+
+	return sorted[mid]
+}
@@ -0,0 +1,104 @@
+package smartcontract
+
+import "testing"
+
+func TestVerifyWifiAgreement(t *testing.T) {
+	agreement := func(bandwidth, uptime float64) *Agreement {
+		return &Agreement{
+			Category:       AgreementCategoryService,
+			Items:          []*AgreementItem{{Code: AgreementItemCodeServiceWifi, MinBandwidthMbps: bandwidth, MinUptimePercent: uptime}},
+			HasPenaltyRule: true,
+			PenaltyRules:   []*PenaltyRule{{Type: PenaltyRuleTypeDiscount, DiscountPercent: 10}},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		agreement *Agreement
+		samples   []float64
+		uptime    float64
+		wantErr   bool
+		satisfied bool
+		severity  string
+		score     float32
+	}{
+		{
+			name:      "bandwidth and uptime reached",
+			agreement: agreement(50, 99),
+			samples:   []float64{60, 40, 55},
+			uptime:    99.5,
+			satisfied: true,
+			score:     1,
+		},
+		{
+			name:      "median of an even number of samples",
+			agreement: agreement(50, 0),
+			samples:   []float64{10, 40, 60, 100},
+			satisfied: true,
+			score:     1,
+		},
+		{
+			name:      "median below the bandwidth",
+			agreement: agreement(50, 0),
+			samples:   []float64{100, 20, 25},
+			score:     0.5,
+		},
+		{
+			name:      "uptime below the agreed uptime",
+			agreement: agreement(0, 90),
+			uptime:    81,
+			score:     0.9,
+		},
+		{
+			name:      "down most of the agreed uptime",
+			agreement: agreement(50, 90),
+			samples:   []float64{50},
+			uptime:    36,
+			severity:  PenaltySeverityCritical,
+			score:     0.4,
+		},
+		{
+			name:      "lower of the bandwidth and uptime scores",
+			agreement: agreement(50, 90),
+			samples:   []float64{40},
+			uptime:    45,
+			score:     0.5,
+		},
+		{
+			name:      "bandwidth without samples",
+			agreement: agreement(50, 0),
+			uptime:    100,
+			wantErr:   true,
+		},
+		{
+			name:      "uptime over 100",
+			agreement: agreement(0, 90),
+			uptime:    101,
+			wantErr:   true,
+		},
+	}
+
+	s := &EvaluationsContract{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := &EvaluationData{Code: AgreementItemCodeServiceWifi, BandwidthSamplesMbps: tt.samples, UptimePercent: tt.uptime}
+			eResult, err := s.VerifyWifiAgreement(nil, tt.agreement, data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if eResult.Satisfied != tt.satisfied {
+				t.Errorf("satisfied = %v, want %v", eResult.Satisfied, tt.satisfied)
+			}
+			if eResult.Severity != tt.severity {
+				t.Errorf("severity = %q, want %q", eResult.Severity, tt.severity)
+			}
+			if !floatEqual(eResult.Score, tt.score) {
+				t.Errorf("score = %v, want %v", eResult.Score, tt.score)
+			}
+		})
+	}
+}
@@ -50,6 +50,7 @@ function printHelp() {
   println "    -ccep <policy>  - Optional, chaincode endorsement policy, using signature policy syntax. The default policy requires an endorsement from Org1 and Org2"
   println "    -cccg <collection-config>  - Optional, path to a private data collections configuration file"
   println "    -cci <fcn name>  - Optional, chaincode init required function to invoke. When provided this function will be invoked after deployment of the chaincode and will define the chaincode as initialization required."
+  println "                     The tourism chaincode is initialized with \"-cci admin:InitLedger\", it is invoked as PlatformAdmin@org1.example.com (role=admin) which is only enrolled when the network is brought up with -ca."
   println
   println "    -h - print this message"
   println
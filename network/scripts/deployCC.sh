@@ -276,6 +276,16 @@ chaincodeInvokeInit() {
   res=$?
   verifyResult $res "Invoke transaction failed on channel '$CHANNEL_NAME' due to uneven number of peer and org parameters "
 
+  # the tourism chaincode is initialized by admin:InitLedger, which must be
+  # submitted by a Org1 client with the role=admin attribute. The peer admins
+  # do not have it, so the platform admin enrolled by the CA is used.
+  setGlobals 1
+  PLATFORM_ADMIN_MSP=${PWD}/organizations/peerOrganizations/org1.example.com/users/PlatformAdmin@org1.example.com/msp
+  if [ ! -d "$PLATFORM_ADMIN_MSP" ]; then
+    fatalln "The init function must be invoked by a client with the role=admin attribute, bring the network up with -ca to enroll PlatformAdmin@org1.example.com"
+  fi
+  export CORE_PEER_MSPCONFIGPATH=$PLATFORM_ADMIN_MSP
+
   # while 'peer chaincode' command can get the orderer endpoint from the
   # peer (if join was successful), let's supply it directly as we know
   # it using the "-o" option
@@ -365,8 +375,8 @@ queryCommitted 1
 queryCommitted 2
 queryCommitted 3
 
-## Invoke the chaincode - this does require that the chaincode have the 'initLedger'
-## method defined
+## Invoke the chaincode - this does require that the chaincode have the init
+## function defined, it is admin:InitLedger for the tourism chaincode
 if [ "$CC_INIT_FCN" = "NA" ]; then
   infoln "Chaincode initialization is not required"
 else